@@ -25,7 +25,10 @@ var addThemeCmd = &cobra.Command{
 	Run:   runAddTheme,
 }
 
+var addSkipCompat bool
+
 func init() {
+	addThemeCmd.Flags().BoolVar(&addSkipCompat, "skip-compat", false, "Add the theme even if it is not compatible with this project")
 	addCmd.AddCommand(addThemeCmd)
 }
 
@@ -36,7 +39,7 @@ func runAddTheme(cmd *cobra.Command, args []string) {
 	if !utils.CheckCommand("git") {
 		utils.PrintError("Git is required for adding themes")
 		fmt.Printf("  %s\n", utils.GetInstallInstructions("git"))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 验证 URL 格式
@@ -45,23 +48,26 @@ func runAddTheme(cmd *cobra.Command, args []string) {
 		utils.PrintInfo("Examples of valid URLs:")
 		fmt.Println("  - https://github.com/user/theme.git")
 		fmt.Println("  - git@github.com:user/theme.git")
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取项目根目录
 	projectRoot, err := utils.GetProjectRoot()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
+	lock := lockProject(projectRoot, cmd)
+	defer lock.Release()
+
 	// 确保 themes 目录存在
 	themesDir := filepath.Join(projectRoot, "themes")
 	if !utils.FileExists(themesDir) {
 		err = utils.CreateDir(themesDir)
 		if err != nil {
 			utils.PrintError(fmt.Sprintf("Failed to create themes directory: %v", err))
-			os.Exit(1)
+			exit(1)
 		}
 		utils.PrintInfo("Created themes directory")
 	}
@@ -73,7 +79,7 @@ func runAddTheme(cmd *cobra.Command, args []string) {
 	// 检查主题是否已存在
 	if utils.FileExists(themePath) {
 		utils.PrintError(fmt.Sprintf("Theme '%s' already exists in themes directory", themeName))
-		os.Exit(1)
+		exit(1)
 	}
 
 	utils.PrintInfo(fmt.Sprintf("Adding theme '%s' from %s...", themeName, gitURL))
@@ -82,7 +88,22 @@ func runAddTheme(cmd *cobra.Command, args []string) {
 	err = utils.RunCommand("git", "clone", gitURL, themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to clone theme repository: %v", err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 读取主题清单并检查兼容性
+	manifest, err := loadThemeManifest(themePath)
+	if err != nil {
+		utils.PrintWarning(fmt.Sprintf("Failed to read theme manifest: %v", err))
+	} else {
+		if manifest.Version != "" {
+			utils.PrintInfo(fmt.Sprintf("Theme: %s %s", manifest.Title(), manifest.Version))
+		}
+		if !enforceThemeCompatibility(projectRoot, manifest, addSkipCompat) {
+			os.RemoveAll(themePath)
+			utils.PrintError(fmt.Sprintf("Theme '%s' is not compatible with this project and was not added", themeName))
+			exit(1)
+		}
 	}
 
 	utils.PrintSuccess(fmt.Sprintf("Theme '%s' added successfully!", themeName))
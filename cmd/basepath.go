@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// basePathListedURLs 是输出中最多列出的无法重写的 URL 数量
+const basePathListedURLs = 10
+
+// normalizeBasePath 将基础路径规范为以 / 开头和结尾的形式，"/" 表示部署在根目录
+func normalizeBasePath(basePath string) (string, error) {
+	basePath = strings.TrimSpace(basePath)
+	if basePath == "" {
+		return "/", nil
+	}
+	if strings.Contains(basePath, "://") || strings.HasPrefix(basePath, "//") {
+		return "", fmt.Errorf("invalid base path '%s', expected a path such as /blog/", basePath)
+	}
+	if strings.ContainsAny(basePath, "?#\\") {
+		return "", fmt.Errorf("invalid base path '%s', it cannot contain '?', '#' or '\\'", basePath)
+	}
+	if basePath = strings.Trim(basePath, "/"); basePath == "" {
+		return "/", nil
+	}
+	return "/" + basePath + "/", nil
+}
+
+// basePathRewriter 为根相对路径添加基础路径，并记录指向本站但无法重写的绝对 URL
+type basePathRewriter struct {
+	BasePath   string
+	SiteHost   string // 站点的主机名，来自 WORDMA_BASE_URL
+	Rewritten  int
+	Unresolved map[string][]string // URL -> 出现的文件
+	file       string
+}
+
+// newBasePathRewriter 创建重写器，baseURL 用于识别指向本站的绝对 URL
+func newBasePathRewriter(basePath, baseURL string) *basePathRewriter {
+	r := &basePathRewriter{BasePath: basePath, Unresolved: make(map[string][]string)}
+	if u, err := url.Parse(baseURL); err == nil {
+		r.SiteHost = strings.ToLower(u.Host)
+	}
+	return r
+}
+
+// rewrite 返回添加了基础路径的 URL
+func (r *basePathRewriter) rewrite(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(trimmed, "//"), strings.Contains(trimmed, "://"):
+		r.checkAbsolute(trimmed)
+		return "", false
+	case !strings.HasPrefix(trimmed, "/"):
+		// 相对路径、锚点、data: 和 mailto: 等不受基础路径影响
+		return "", false
+	case trimmed+"/" == r.BasePath || strings.HasPrefix(trimmed, r.BasePath):
+		return "", false
+	}
+	r.Rewritten++
+	return r.BasePath + strings.TrimPrefix(trimmed, "/"), true
+}
+
+// checkAbsolute 记录指向本站但缺少基础路径的绝对 URL
+func (r *basePathRewriter) checkAbsolute(value string) {
+	if r.SiteHost == "" {
+		return
+	}
+	raw := value
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || strings.ToLower(u.Host) != r.SiteHost {
+		return
+	}
+	if u.Path+"/" == r.BasePath || strings.HasPrefix(u.Path, r.BasePath) {
+		return
+	}
+	files := r.Unresolved[value]
+	if len(files) == 0 || files[len(files)-1] != r.file {
+		r.Unresolved[value] = append(files, r.file)
+	}
+}
+
+// rewriteDir 重写目录中所有 HTML 和 CSS 文件，返回修改的文件数量
+func (r *basePathRewriter) rewriteDir(dir string) (int, error) {
+	changed := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		fileType := assetType(path)
+		if fileType != "html" && fileType != "css" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(dir, path)
+		r.file = filepath.ToSlash(relPath)
+
+		var result []byte
+		if fileType == "html" {
+			result = utils.RewriteHTMLURLs(data, r.rewrite)
+		} else {
+			result = utils.RewriteCSSURLs(data, r.rewrite)
+		}
+		if string(result) == string(data) {
+			return nil
+		}
+		changed++
+		return os.WriteFile(path, result, info.Mode().Perm())
+	})
+	return changed, err
+}
+
+// rewriteBasePath 为构建输出中的根相对 URL 添加 --base-path，并报告无法重写的绝对 URL
+func (b *themeBuild) rewriteBasePath(outDir string) error {
+	if b.BasePath == "" || b.BasePath == "/" {
+		return nil
+	}
+
+	rewriter := newBasePathRewriter(b.BasePath, b.WordmaEnv.BaseURL)
+	changed, err := rewriter.rewriteDir(outDir)
+	if err != nil {
+		return fmt.Errorf("failed to apply base path '%s': %v", b.BasePath, err)
+	}
+	utils.FprintInfo(b.Out, fmt.Sprintf("Rewrote %d URL(s) in %d file(s) for base path '%s'", rewriter.Rewritten, changed, b.BasePath))
+
+	if len(rewriter.Unresolved) == 0 {
+		return nil
+	}
+	urls := make([]string, 0, len(rewriter.Unresolved))
+	for u := range rewriter.Unresolved {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	utils.FprintWarning(b.Out, fmt.Sprintf("%d absolute URL(s) point to %s outside base path '%s' and were not rewritten:",
+		len(urls), rewriter.SiteHost, b.BasePath))
+	for i, u := range urls {
+		if i == basePathListedURLs {
+			fmt.Fprintf(b.Out, "  ... and %d more\n", len(urls)-i)
+			break
+		}
+		fmt.Fprintf(b.Out, "  %s (%s)\n", u, strings.Join(rewriter.Unresolved[u], ", "))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestNormalizeBasePath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: "/"},
+		{input: "/", want: "/"},
+		{input: "blog", want: "/blog/"},
+		{input: "/blog/", want: "/blog/"},
+		{input: "//x", wantErr: true},
+		{input: "a?b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeBasePath(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeBasePath(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeBasePath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"wordma-cli/utils"
+)
+
+// budgetViolation 是一条超出体积预算的记录
+type budgetViolation struct {
+	Path    string
+	Message string
+}
+
+// checkBudgets 用报告中的文件统计检查体积预算
+func checkBudgets(budgets *BudgetConfig, report *buildReport) []budgetViolation {
+	var violations []budgetViolation
+	exceeds := func(size int64, limit SizeLimit) bool {
+		return limit > 0 && size > int64(limit)
+	}
+
+	if exceeds(report.Size, budgets.Total) {
+		violations = append(violations, budgetViolation{
+			Message: fmt.Sprintf("total size %s exceeds budget %s", utils.FormatSize(report.Size), utils.FormatSize(int64(budgets.Total))),
+		})
+	}
+	if exceeds(report.GzipSize, budgets.TotalGzip) {
+		violations = append(violations, budgetViolation{
+			Message: fmt.Sprintf("total gzip size %s exceeds budget %s", utils.FormatSize(report.GzipSize), utils.FormatSize(int64(budgets.TotalGzip))),
+		})
+	}
+
+	paths := make([]string, 0, len(report.files))
+	for path := range report.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := report.files[path]
+		if file.Type == "image" && exceeds(file.Size, budgets.MaxImage) {
+			violations = append(violations, budgetViolation{
+				Path:    path,
+				Message: fmt.Sprintf("image size %s exceeds budget %s", utils.FormatSize(file.Size), utils.FormatSize(int64(budgets.MaxImage))),
+			})
+		}
+
+		for _, budget := range budgets.Files {
+			if !utils.MatchGlob(budget.Glob, path) {
+				continue
+			}
+			if exceeds(file.Size, budget.Size) {
+				violations = append(violations, budgetViolation{
+					Path:    path,
+					Message: fmt.Sprintf("size %s exceeds \"%s\" budget %s", utils.FormatSize(file.Size), budget.Glob, utils.FormatSize(int64(budget.Size))),
+				})
+			}
+			if exceeds(file.GzipSize, budget.Gzip) {
+				violations = append(violations, budgetViolation{
+					Path:    path,
+					Message: fmt.Sprintf("gzip size %s exceeds \"%s\" budget %s", utils.FormatSize(file.GzipSize), budget.Glob, utils.FormatSize(int64(budget.Gzip))),
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// printBudgetViolations 输出超出预算的文件
+func printBudgetViolations(w io.Writer, violations []budgetViolation) {
+	for _, violation := range violations {
+		if violation.Path == "" {
+			fmt.Fprintf(w, "  - %s\n", violation.Message)
+			continue
+		}
+		fmt.Fprintf(w, "  - %s: %s\n", violation.Path, violation.Message)
+	}
+}
@@ -2,46 +2,219 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"wordma-cli/utils"
 )
 
 var buildCmd = &cobra.Command{
-	Use:   "build <theme-name>",
-	Short: "Build a theme for production",
-	Long:  "Build the specified theme for production deployment",
-	Args:  cobra.ExactArgs(1),
-	Run:   runBuild,
+	Use:   "build [theme-name...]",
+	Short: "Build themes for production",
+	Long: `Build the specified themes for production deployment.
+When several themes are given (or --all is used) they are built in parallel,
+each writing its output to its own directory.`,
+	Args: validateBuildArgs,
+	Run:  runBuild,
 }
 
-func runBuild(cmd *cobra.Command, args []string) {
-	themeName := args[0]
+var (
+	buildSkipValidate bool
+	buildSkipCompat   bool
+	buildAll          bool
+	buildJobs         int
+	buildKeep         int
+	buildForce        bool
+	buildSkipBudgets  bool
+	buildEnvName      string
+	buildBasePath     string
+	buildPrecompress  bool
+	buildHashAssets   bool
+	buildSitemap      bool
+	buildSearchIndex  bool
+	buildFeeds        bool
+	buildSRI          bool
+	buildHosting      string
+)
+
+func init() {
+	buildCmd.Flags().BoolVar(&buildSkipValidate, "skip-validate", false, "Skip validating the theme config against its schema")
+	buildCmd.Flags().BoolVar(&buildSkipCompat, "skip-compat", false, "Build even if the theme is not compatible with this project")
+	buildCmd.Flags().BoolVar(&buildAll, "all", false, "Build all themes in the themes directory")
+	buildCmd.Flags().IntVarP(&buildJobs, "jobs", "j", 2, "Maximum number of themes to build in parallel")
+	buildCmd.Flags().IntVar(&buildKeep, "keep", 3, "Number of releases to keep for rollback")
+	buildCmd.Flags().BoolVar(&buildForce, "force", false, "Build even if nothing changed since the current release")
+	buildCmd.Flags().StringVar(&buildEnvName, "env", "production", "Environment whose .env files are loaded")
+	buildCmd.Flags().BoolVar(&buildSkipBudgets, "skip-budgets", false, "Do not fail the build when the output exceeds its size budgets")
+	buildCmd.Flags().BoolVar(&buildPrecompress, "precompress", false, "Write .gz (and .br when brotli is installed) files next to compressible output files")
+	buildCmd.Flags().BoolVar(&buildHashAssets, "hash-assets", false, "Rename static assets to content-hashed names and rewrite references to them")
+	buildCmd.Flags().BoolVar(&buildSitemap, "sitemap", false, "Generate sitemap.xml and robots.txt from the HTML pages in the output")
+	buildCmd.Flags().BoolVar(&buildFeeds, "feeds", false, "Generate feed.xml (RSS), atom.xml and feed.json from the dated pages in the output")
+	buildCmd.Flags().BoolVar(&buildSRI, "sri", false, "Add integrity attributes to scripts and stylesheets and suggest a Content-Security-Policy")
+	buildCmd.Flags().StringVar(&buildHosting, "hosting", "", "Write redirects and headers from the project config for this target: netlify, cloudflare, nginx, github-pages or none")
+	buildCmd.Flags().BoolVar(&buildSearchIndex, "search-index", false, "Generate a JSON search index from the HTML pages in the output")
+	buildCmd.Flags().StringVar(&buildBasePath, "base-path", "/", "Path the site is served from, such as /blog/; root-relative URLs in HTML and CSS are rewritten")
+}
+
+// validateBuildArgs 检查主题名称与 --all 的组合
+func validateBuildArgs(cmd *cobra.Command, args []string) error {
+	if buildAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be combined with theme names")
+	}
+	if !buildAll && len(args) == 0 {
+		return fmt.Errorf("requires at least 1 theme name, or --all")
+	}
+	return nil
+}
+
+// themeBuild 表示一次主题构建
+type themeBuild struct {
+	Name        string
+	ProjectRoot string
+	ThemePath   string
+	Manifest    *ThemeManifest
+	OutDir      string // 构建输出目录（绝对路径）
+	OutDirSet   bool   // 输出目录是否由主题清单或项目配置声明
+	SharedOut   bool   // 主题可能忽略 WORDMA_OUT_DIR 而输出到共享的 .deploy/.temp
+	Budgets     *BudgetConfig
+	BasePath    string // 规范化的 --base-path，"/" 表示部署在根目录
+	Precompress *utils.PrecompressOptions
+	HashAssets  *hashAssetsSettings
+	Sitemap     *sitemapSettings
+	Feeds       *feedSettings
+	Search      *searchSettings
+	SRI         *sriSettings
+	CSP         string // 生成的 Content-Security-Policy 建议
+	Hosting     *hostingSettings
+	NginxConfig []byte // 部署目标为 nginx 时生成的配置片段
+	Env         *dotEnv
+	WordmaEnv   *wordmaEnv
+	Hooks       map[string]HookCommands
+	Out         io.Writer
+	Duration    time.Duration
+	Skipped     bool // 输入没有变化，跳过了构建
+	Err         error
+}
 
+func runBuild(cmd *cobra.Command, args []string) {
 	// 检查 pnpm 是否安装
 	if !utils.CheckCommand("pnpm") {
 		utils.PrintError("pnpm is required for building themes")
 		fmt.Printf("  %s\n", utils.GetInstallInstructions("pnpm"))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取项目根目录
 	projectRoot, err := utils.GetProjectRoot()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	lock := lockProject(projectRoot, cmd)
+	defer lock.Release()
+
+	themeNames := args
+	if buildAll {
+		themeNames, err = listBuildableThemes(projectRoot)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to list themes: %v", err))
+			exit(1)
+		}
+		if len(themeNames) == 0 {
+			utils.PrintError("No themes with a package.json found in themes directory")
+			exit(1)
+		}
+	}
+
+	projectConfig, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		exit(1)
+	}
+
+	basePath, err := normalizeBasePath(buildBasePath)
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+
+	// 构建前逐个检查主题，任何一个不满足条件都不开始构建
+	var builds []*themeBuild
+	for _, themeName := range themeNames {
+		build := prepareThemeBuild(projectRoot, themeName, basePath, projectConfig)
+		build.Precompress = resolvePrecompress(projectConfig.precompress(themeName), buildPrecompress, cmd.Flags().Changed("precompress"))
+		build.HashAssets = resolveHashAssets(projectConfig.hashAssets(themeName), buildHashAssets, cmd.Flags().Changed("hash-assets"))
+		build.Sitemap = resolveSitemap(projectConfig.sitemap(themeName), buildSitemap, cmd.Flags().Changed("sitemap"))
+		build.Feeds = resolveFeeds(projectConfig.feeds(themeName), buildFeeds, cmd.Flags().Changed("feeds"))
+		build.Search = resolveSearch(projectConfig.search(themeName), build.Manifest, buildSearchIndex, cmd.Flags().Changed("search-index"))
+		build.SRI = resolveSRI(projectConfig.sri(themeName), buildSRI, cmd.Flags().Changed("sri"))
+		if build.Hosting, err = resolveHosting(projectConfig.hosting(themeName), buildHosting, cmd.Flags().Changed("hosting")); err != nil {
+			utils.PrintError(fmt.Sprintf("Invalid hosting config for theme '%s': %v", themeName, err))
+			exit(1)
+		}
+		// 依次构建时不会同时使用 .deploy/.temp，按可能使用处理
+		build.SharedOut = len(themeNames) == 1 || buildJobs <= 1 || mayUseSharedOutDir(build)
+		builds = append(builds, build)
+	}
+
+	if len(builds) == 1 {
+		build := builds[0]
+		build.Out = color.Output
+		if err := build.run(); err != nil {
+			utils.PrintError(err.Error())
+			exit(1)
+		}
+		if !build.Skipped {
+			utils.PrintSuccess(fmt.Sprintf("Theme '%s' built successfully!", build.Name))
+		}
+		return
+	}
+
+	runParallelBuilds(builds, buildJobs)
+	printBuildSummary(builds)
+
+	for _, build := range builds {
+		if build.Err != nil {
+			exit(1)
+		}
+	}
+}
+
+// listBuildableThemes 列出 themes 目录中包含 package.json 的主题
+func listBuildableThemes(projectRoot string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(projectRoot, "themes"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && utils.FileExists(filepath.Join(projectRoot, "themes", entry.Name(), "package.json")) {
+			names = append(names, entry.Name())
+		}
 	}
+	sort.Strings(names)
+	return names, nil
+}
 
+// prepareThemeBuild 检查主题目录、兼容性和配置，有问题时打印错误并退出
+func prepareThemeBuild(projectRoot, themeName, basePath string, projectConfig *ProjectConfig) *themeBuild {
 	// 构建主题目录路径
 	themePath := filepath.Join(projectRoot, "themes", themeName)
-	
+
 	// 检查主题目录是否存在
 	if !utils.FileExists(themePath) {
 		utils.PrintError(fmt.Sprintf("Theme directory '%s' does not exist", themePath))
 		utils.PrintInfo("Available themes:")
-		
+
 		themesDir := filepath.Join(projectRoot, "themes")
 		if utils.FileExists(themesDir) {
 			entries, readErr := os.ReadDir(themesDir)
@@ -53,54 +226,455 @@ func runBuild(cmd *cobra.Command, args []string) {
 				}
 			}
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查 package.json 是否存在
 	packageJsonPath := filepath.Join(themePath, "package.json")
 	if !utils.FileExists(packageJsonPath) {
 		utils.PrintError(fmt.Sprintf("No package.json found in theme directory '%s'", themePath))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 检查主题兼容性
+	manifest, err := loadThemeManifest(themePath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read theme manifest: %v", err))
+		exit(1)
+	}
+	if !enforceThemeCompatibility(projectRoot, manifest, buildSkipCompat) {
+		exit(1)
 	}
 
-	utils.PrintInfo(fmt.Sprintf("Building theme '%s' for production...", themeName))
-	
-	// 执行 pnpm run build
-	err = utils.RunCommandInDir(themePath, "pnpm", "run", "build")
+	// 校验主题配置
+	if !buildSkipValidate {
+		checkThemeConfig(projectRoot, themeName, themePath)
+	}
+
+	env := requireDotEnv(projectRoot, themePath, buildEnvName)
+
+	outDir, outDirSet, err := resolveBuildOutDir(projectRoot, themeName, themePath, manifest, projectConfig)
 	if err != nil {
-		utils.PrintError(fmt.Sprintf("Failed to build theme: %v", err))
-		os.Exit(1)
-	}
-
-	// 重命名 .deploy/.temp 文件夹为主题名称
-	deployDir := filepath.Join(projectRoot, ".deploy")
-	tempDir := filepath.Join(deployDir, ".temp")
-	targetDir := filepath.Join(deployDir, themeName)
-
-	// 检查 .temp 目录是否存在
-	if utils.FileExists(tempDir) {
-		utils.PrintInfo("Renaming build output directory...")
-		
-		// 如果目标目录已存在，先删除
-		if utils.FileExists(targetDir) {
-			err = os.RemoveAll(targetDir)
-			if err != nil {
-				utils.PrintError(fmt.Sprintf("Failed to remove existing directory '%s': %v", targetDir, err))
-				os.Exit(1)
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+
+	wordmaEnv := newWordmaEnv(modeProduction, projectRoot, themeName, env, projectConfig)
+	wordmaEnv.OutDir = outDir
+	wordmaEnv.BasePath = basePath
+
+	return &themeBuild{
+		Name:        themeName,
+		ProjectRoot: projectRoot,
+		ThemePath:   themePath,
+		Manifest:    manifest,
+		OutDir:      outDir,
+		OutDirSet:   outDirSet,
+		Budgets:     projectConfig.budgets(themeName),
+		BasePath:    basePath,
+		Env:         env,
+		WordmaEnv:   wordmaEnv,
+		Hooks:       projectConfig.Hooks,
+		Out:         color.Output,
+	}
+}
+
+// resolveBuildOutDir 确定主题的构建输出目录：项目配置 themes.<name>.outDir 优先，
+// 其次是主题清单的 wordma.outDir，都未声明时使用 .deploy/.temp-<theme>
+func resolveBuildOutDir(projectRoot, themeName, themePath string, manifest *ThemeManifest, projectConfig *ProjectConfig) (string, bool, error) {
+	var outDir, source string
+	if dir := projectConfig.theme(themeName).OutDir; dir != "" {
+		outDir, source = filepath.Join(projectRoot, filepath.FromSlash(dir)), projectConfigFile
+	} else if manifest.OutDir != "" {
+		outDir, source = filepath.Join(themePath, filepath.FromSlash(manifest.OutDir)), "package.json"
+	} else {
+		return filepath.Join(projectRoot, ".deploy", ".temp-"+themeName), false, nil
+	}
+
+	// 输出目录构建后会被移走，不能是主题目录、项目根目录或它们的上级
+	for _, protected := range []string{themePath, projectRoot} {
+		if rel, err := filepath.Rel(outDir, protected); err == nil && !strings.HasPrefix(rel, "..") {
+			return "", false, fmt.Errorf("invalid output directory '%s' declared in %s: it must be a subdirectory of the theme or project", outDir, source)
+		}
+	}
+	return outDir, true, nil
+}
+
+// sharedOutDirMu 保证同一时间只有一个主题使用共享的 .deploy/.temp
+var sharedOutDirMu sync.Mutex
+
+// mayUseSharedOutDir 判断主题是否可能输出到共享的 .deploy/.temp：主题没有声明输出目录，
+// 并且还没有构建过或者最近一次构建的输出不在 .deploy/.temp-<theme> 中
+func mayUseSharedOutDir(b *themeBuild) bool {
+	if b.OutDirSet {
+		return false
+	}
+	index, err := loadReleaseIndex(b.ProjectRoot, b.Name)
+	if err != nil || len(index.Releases) == 0 {
+		return true
+	}
+	return index.Releases[len(index.Releases)-1].OutDir != b.relPath(b.OutDir)
+}
+
+// runParallelBuilds 以最多 jobs 个并发构建全部主题，每个主题的输出带有主题名前缀
+func runParallelBuilds(builds []*themeBuild, jobs int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	width := 0
+	for _, build := range builds {
+		if len(build.Name) > width {
+			width = len(build.Name)
+		}
+	}
+
+	utils.PrintInfo(fmt.Sprintf("Building %d themes with up to %d parallel job(s)...", len(builds), jobs))
+
+	var shared []string
+	for _, build := range builds {
+		if build.SharedOut {
+			shared = append(shared, build.Name)
+		}
+	}
+	if len(shared) > 1 && jobs > 1 {
+		utils.PrintInfo(fmt.Sprintf("Building %s one at a time, they may write to the shared .deploy/.temp directory", strings.Join(shared, ", ")))
+	}
+
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, jobs)
+	colors := []string{"cyan", "magenta", "blue", "yellow", "green"}
+
+	for i, build := range builds {
+		prefix := utils.ColorText(fmt.Sprintf("[%-*s] ", width, build.Name), colors[i%len(colors)])
+		writer := utils.NewPrefixWriter(color.Output, prefix, &outputMu)
+		build.Out = writer
+
+		wg.Add(1)
+		go func(build *themeBuild, writer *utils.PrefixWriter) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			build.Err = build.run()
+			if build.Err != nil {
+				utils.FprintError(build.Out, build.Err.Error())
+			} else if !build.Skipped {
+				utils.FprintSuccess(build.Out, fmt.Sprintf("Theme '%s' built successfully!", build.Name))
 			}
+			writer.Flush()
+		}(build, writer)
+	}
+
+	wg.Wait()
+}
+
+// printBuildSummary 输出每个主题的构建结果和耗时
+func printBuildSummary(builds []*themeBuild) {
+	fmt.Println()
+	utils.PrintInfo("Build summary:")
+
+	width := 0
+	for _, build := range builds {
+		if len(build.Name) > width {
+			width = len(build.Name)
 		}
-		
-		// 重命名 .temp 为主题名称
-		err = os.Rename(tempDir, targetDir)
-		if err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to rename build directory: %v", err))
-			os.Exit(1)
+	}
+
+	failed := 0
+	for _, build := range builds {
+		duration := build.Duration.Round(100 * time.Millisecond).String()
+		if build.Err != nil {
+			failed++
+			fmt.Printf("  %s %-*s  %8s  %s\n", utils.ColorText("✗", "red"), width, build.Name, duration, build.Err.Error())
+		} else if build.Skipped {
+			fmt.Printf("  %s %-*s  %8s  %s\n", utils.ColorText("✓", "green"), width, build.Name, duration, "up to date, skipped")
+		} else {
+			fmt.Printf("  %s %-*s  %8s\n", utils.ColorText("✓", "green"), width, build.Name, duration)
 		}
-		
-		utils.PrintSuccess(fmt.Sprintf("Build output moved to '.deploy/%s'", themeName))
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		utils.PrintError(fmt.Sprintf("%d of %d theme(s) failed to build", failed, len(builds)))
 	} else {
-		utils.PrintWarning("No .temp directory found in .deploy folder")
+		utils.PrintSuccess(fmt.Sprintf("All %d themes built successfully!", len(builds)))
 	}
+}
 
-	utils.PrintSuccess(fmt.Sprintf("Theme '%s' built successfully!", themeName))
-}
\ No newline at end of file
+// run 执行 pnpm run build，将构建输出保存为新版本并切换 .deploy/<theme>
+func (b *themeBuild) run() error {
+	start := time.Now()
+	defer func() { b.Duration = time.Since(start) }()
+
+	// 覆盖层锁保证构建期间 dev 或 update theme 不会改写主题文件
+	overlayLock, err := lockThemeOverlay(b.ProjectRoot, b.Name, "wordma build", b.Out)
+	if err != nil {
+		return err
+	}
+	defer overlayLock.Release()
+
+	// 先恢复上次异常退出残留的覆盖层，保证指纹只反映主题本身的文件
+	if err := restoreThemeOverlay(b.ProjectRoot, b.Name, b.ThemePath); err != nil {
+		return fmt.Errorf("failed to restore previous overlay: %v", err)
+	}
+
+	// prebuild 钩子可能生成构建输入，因此在计算指纹之前执行
+	if err := b.runHook("prebuild"); err != nil {
+		return err
+	}
+
+	fingerprint, err := computeBuildFingerprint(b)
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint build inputs: %v", err)
+	}
+	if !buildForce && isBuildUpToDate(b.ProjectRoot, b.Name, fingerprint) {
+		b.Skipped = true
+		utils.FprintSuccess(b.Out, fmt.Sprintf("Theme '%s' is up to date, skipping build (use --force to rebuild)", b.Name))
+		return nil
+	}
+
+	utils.FprintInfo(b.Out, fmt.Sprintf("Building theme '%s' for production...", b.Name))
+
+	// 未声明输出目录时构建到 .deploy/.temp-<theme>，构建前清空；
+	// 声明的目录可能包含主题自己的文件，不清空，通过构建后的修改时间判断是否为新输出
+	deployDir := filepath.Join(b.ProjectRoot, ".deploy")
+	outDir := b.OutDir
+
+	// 可能输出到共享 .deploy/.temp 的主题依次构建，直到输出被移入版本目录
+	unlockSharedOut := func() {}
+	if b.SharedOut {
+		sharedOutDirMu.Lock()
+		var once sync.Once
+		unlockSharedOut = func() { once.Do(sharedOutDirMu.Unlock) }
+	}
+	defer unlockSharedOut()
+
+	if !b.OutDirSet {
+		if err := os.RemoveAll(outDir); err != nil {
+			return fmt.Errorf("failed to clean build directory '%s': %v", outDir, err)
+		}
+	}
+	// 文件系统的修改时间精度有限，留出少量余量
+	buildStart := time.Now().Add(-2 * time.Second)
+
+	// 执行 pnpm run build（期间应用 overrides/<theme>/ 覆盖层），输出中的密钥被替换
+	err = withThemeOverlay(b.ProjectRoot, b.Name, b.ThemePath, b.Out, func() error {
+		out, flush := b.Env.maskOutput(b.Out)
+		defer flush()
+		return utils.RunCommandWithOptions(utils.CommandOptions{
+			Dir:    b.ThemePath,
+			Env:    b.buildEnv(),
+			Stdout: out,
+			Stderr: out,
+		}, "pnpm", "run", "build")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build theme: %v", err)
+	}
+
+	// 不支持 WORDMA_OUT_DIR 的旧主题仍然输出到共享的 .deploy/.temp
+	if !b.OutDirSet && !utils.FileExists(outDir) {
+		legacyDir := filepath.Join(deployDir, ".temp")
+		if !utils.FileExists(legacyDir) {
+			return fmt.Errorf("build produced no output in '%s', declare \"wordma.outDir\" in the theme's package.json or set themes.%s.outDir in %s",
+				b.relPath(outDir), b.Name, projectConfigFile)
+		}
+		if !b.SharedOut {
+			// 其他主题可能同时写入了 .deploy/.temp，不能确定其中是这个主题的输出
+			return fmt.Errorf("theme wrote to the shared .deploy/.temp directory although it used WORDMA_OUT_DIR before, build it again with --jobs 1")
+		}
+		utils.FprintWarning(b.Out, "Theme ignores WORDMA_OUT_DIR and wrote to the shared .deploy/.temp directory, it is built one at a time with other such themes")
+		outDir = legacyDir
+	}
+
+	if err := checkFreshOutput(outDir, buildStart); err != nil {
+		return fmt.Errorf("%s: %v", b.relPath(outDir), err)
+	}
+
+	// 部署在子目录时为输出中的根相对 URL 添加基础路径
+	if err := b.rewriteBasePath(outDir); err != nil {
+		return err
+	}
+	if b.HashAssets != nil {
+		if err := b.hashAssets(outDir); err != nil {
+			return err
+		}
+	}
+	if b.Sitemap != nil {
+		if err := b.generateSitemap(outDir); err != nil {
+			return err
+		}
+	}
+	if b.Feeds != nil {
+		if err := b.generateFeeds(outDir); err != nil {
+			return err
+		}
+	}
+	if b.Search != nil {
+		if err := b.generateSearchIndex(outDir); err != nil {
+			return err
+		}
+	}
+	// integrity 在其他步骤之后计算，此后脚本和样式表不再变化
+	if b.SRI != nil {
+		if err := b.addIntegrity(outDir); err != nil {
+			return err
+		}
+	}
+	// 托管配置最后写入，可以包含 CSP 建议，跳转页面也不会被其他步骤处理
+	if b.Hosting != nil {
+		if err := b.writeHostingConfig(outDir); err != nil {
+			return err
+		}
+	}
+
+	// 构建输出保存为新版本，再原子地切换为 .deploy/<theme>
+	index, release, err := createRelease(b.ProjectRoot, b.Name, outDir, b.Manifest, fingerprint, b.BasePath)
+	if err != nil {
+		return err
+	}
+	unlockSharedOut()
+
+	// 在切换和清理旧版本之前生成报告，此时上一个上线版本还在
+	report, err := b.createReport(release.ID, index.Current)
+	if err != nil {
+		utils.FprintWarning(b.Out, fmt.Sprintf("Failed to create build report: %v", err))
+	}
+
+	// 超出体积预算的版本不上线
+	if b.Budgets != nil && !buildSkipBudgets {
+		if err := b.checkBudgets(report, err); err != nil {
+			if discardErr := discardRelease(b.ProjectRoot, b.Name, index, release.ID); discardErr != nil {
+				utils.FprintWarning(b.Out, fmt.Sprintf("Failed to remove release '%s': %v", release.ID, discardErr))
+			}
+			return err
+		}
+	}
+
+	// 预压缩在统计报告之后进行，压缩文件不计入报告和预算
+	if b.Precompress != nil {
+		if err := b.precompress(filepath.Join(getReleasesDir(b.ProjectRoot, b.Name), release.ID), index.Current); err != nil {
+			if discardErr := discardRelease(b.ProjectRoot, b.Name, index, release.ID); discardErr != nil {
+				utils.FprintWarning(b.Out, fmt.Sprintf("Failed to remove release '%s': %v", release.ID, discardErr))
+			}
+			return err
+		}
+	}
+
+	if b.NginxConfig != nil {
+		if err := b.saveNginxConfig(release.ID); err != nil {
+			if discardErr := discardRelease(b.ProjectRoot, b.Name, index, release.ID); discardErr != nil {
+				utils.FprintWarning(b.Out, fmt.Sprintf("Failed to remove release '%s': %v", release.ID, discardErr))
+			}
+			return err
+		}
+	}
+
+	if err := activateRelease(b.ProjectRoot, b.Name, index, release.ID); err != nil {
+		return err
+	}
+	utils.FprintSuccess(b.Out, fmt.Sprintf("Release '%s' is live at '.deploy/%s'", release.ID, b.Name))
+
+	if report != nil {
+		b.saveReport(report)
+	}
+	if b.CSP != "" {
+		b.saveCSP()
+	}
+	if b.NginxConfig != nil {
+		utils.FprintInfo(b.Out, fmt.Sprintf("Hosting config for nginx written to '%s'", b.relPath(getNginxConfigPath(b.ProjectRoot, b.Name))))
+	}
+	if err := pruneReleases(b.ProjectRoot, b.Name, index, buildKeep); err != nil {
+		utils.FprintWarning(b.Out, fmt.Sprintf("Failed to remove old releases: %v", err))
+	}
+
+	return b.runHook("postbuild", "WORDMA_RELEASE="+release.ID)
+}
+
+// runHook 执行构建钩子，输出中的密钥被替换
+func (b *themeBuild) runHook(name string, extraEnv ...string) error {
+	out, flush := b.Env.maskOutput(b.Out)
+	defer flush()
+	return runHook(b.ProjectRoot, b.Hooks, name, b.WordmaEnv.environ(extraEnv...), out)
+}
+
+// createReport 统计新版本的输出并与上一个上线版本比较
+func (b *themeBuild) createReport(release, previous string) (*buildReport, error) {
+	releasesDir := getReleasesDir(b.ProjectRoot, b.Name)
+	previousDir := ""
+	if previous != "" {
+		previousDir = filepath.Join(releasesDir, previous)
+	}
+
+	return newBuildReport(b.Name, release, filepath.Join(releasesDir, release), previous, previousDir)
+}
+
+// checkBudgets 检查新版本是否超出体积预算，超出时输出报告和超出的项
+func (b *themeBuild) checkBudgets(report *buildReport, reportErr error) error {
+	if report == nil {
+		return fmt.Errorf("cannot check size budgets without a build report: %v", reportErr)
+	}
+
+	violations := checkBudgets(b.Budgets, report)
+	if len(violations) == 0 {
+		utils.FprintInfo(b.Out, "Build output is within its size budgets")
+		return nil
+	}
+
+	printBuildReport(b.Out, report)
+	utils.FprintError(b.Out, fmt.Sprintf("Build output exceeds %d size budget(s):", len(violations)))
+	printBudgetViolations(b.Out, violations)
+	utils.FprintInfo(b.Out, "The previous release stays live, use --skip-budgets to deploy anyway")
+	return fmt.Errorf("build output exceeds its size budgets")
+}
+
+// saveReport 以表格输出报告并保存为 JSON，失败不影响构建结果
+func (b *themeBuild) saveReport(report *buildReport) {
+	printBuildReport(b.Out, report)
+
+	reportPath := getReportPath(b.ProjectRoot, b.Name)
+	if err := saveBuildReport(reportPath, report); err != nil {
+		utils.FprintWarning(b.Out, fmt.Sprintf("Failed to save build report: %v", err))
+		return
+	}
+	utils.FprintInfo(b.Out, fmt.Sprintf("Build report saved to '%s'", b.relPath(reportPath)))
+}
+
+// buildEnv 返回传给主题构建脚本的环境变量
+func (b *themeBuild) buildEnv() []string {
+	return b.WordmaEnv.environ()
+}
+
+// relPath 返回相对于项目根目录的路径，用于输出信息
+func (b *themeBuild) relPath(path string) string {
+	if rel, err := filepath.Rel(b.ProjectRoot, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// isStepEnabled 判断项目配置中已设置的可选构建步骤是否启用：命令行参数（flagSet 表示
+// 是否指定）优先，其次是配置的 enabled，默认启用
+func isStepEnabled(enabled *bool, flag, flagSet bool) bool {
+	if flagSet {
+		return flag
+	}
+	return enabled == nil || *enabled
+}
+
+// checkFreshOutput 检查输出目录存在，并且包含本次构建生成的文件
+func checkFreshOutput(outDir string, since time.Time) error {
+	if !utils.FileExists(outDir) {
+		return fmt.Errorf("build did not create the output directory")
+	}
+	fresh, err := utils.HasFilesModifiedSince(outDir, since)
+	if err != nil {
+		return fmt.Errorf("failed to inspect build output: %v", err)
+	}
+	if !fresh {
+		return fmt.Errorf("output directory was not updated by this build, it only contains stale files")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check a built site",
+	Long:  "Commands for checking the live build of a theme in .deploy/<theme>",
+}
+
+// builtSite 是检查命令使用的主题上线目录
+type builtSite struct {
+	ProjectRoot string
+	Theme       string
+	Dir         string // .deploy/<theme>
+	BasePath    string // 构建时使用的 --base-path
+	BaseURL     string
+	Config      *ProjectConfig
+}
+
+// requireBuiltSite 返回主题的上线目录和构建设置，主题还没有构建时打印错误并退出
+func requireBuiltSite(themeName string) *builtSite {
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		os.Exit(1)
+	}
+
+	liveDir := getLiveDir(projectRoot, themeName)
+	if !utils.FileExists(liveDir) {
+		utils.PrintError(fmt.Sprintf("Theme '%s' has not been built, '.deploy/%s' does not exist", themeName, themeName))
+		utils.PrintInfo(fmt.Sprintf("Run 'wordma build %s' first", themeName))
+		os.Exit(1)
+	}
+
+	projectConfig, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		os.Exit(1)
+	}
+
+	site := &builtSite{
+		ProjectRoot: projectRoot,
+		Theme:       themeName,
+		Dir:         liveDir,
+		BasePath:    "/",
+		BaseURL:     resolveBaseURL(projectConfig, themeName, nil),
+		Config:      projectConfig,
+	}
+	if index, err := loadReleaseIndex(projectRoot, themeName); err == nil {
+		if i := index.find(index.Current); i >= 0 && index.Releases[i].BasePath != "" {
+			site.BasePath = index.Releases[i].BasePath
+		}
+	}
+	return site
+}
+
+// htmlPages 返回站点中全部 HTML 页面的相对路径（以 / 分隔），按路径排序
+func (s *builtSite) htmlPages() ([]string, error) {
+	var pages []string
+	err := filepath.Walk(s.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || assetType(path) != "html" {
+			return nil
+		}
+		relPath, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, filepath.ToSlash(relPath))
+		return nil
+	})
+	sort.Strings(pages)
+	return pages, err
+}
+
+// readPage 读取站点中的文件
+func (s *builtSite) readPage(relPath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Dir, filepath.FromSlash(relPath)))
+}
+
+// findFile 返回站内路径对应的文件：目录对应其中的 index.html，
+// 没有扩展名的路径也可以对应同名的 .html 文件。不存在时返回 false
+func (s *builtSite) findFile(target string) (string, bool) {
+	var candidates []string
+	switch {
+	case target == "":
+		candidates = []string{"index.html"}
+	case target[len(target)-1] == '/':
+		candidates = []string{target + "index.html"}
+	default:
+		candidates = []string{target, target + "/index.html", target + ".html"}
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(candidate)))
+		if err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
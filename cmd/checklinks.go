@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var checkLinksCmd = &cobra.Command{
+	Use:   "links <theme>",
+	Short: "Find broken links, assets and anchors in a built theme",
+	Long: `Parse every HTML page in .deploy/<theme> and check that internal links, assets
+and #fragment anchors exist in the built output. With --external outbound URLs
+are requested as well; their results are cached in .wordma/cache/links.json.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCheckLinks,
+}
+
+var (
+	checkLinksExternal    bool
+	checkLinksConcurrency int
+	checkLinksTimeout     time.Duration
+	checkLinksCacheTTL    time.Duration
+)
+
+func init() {
+	checkLinksCmd.Flags().BoolVar(&checkLinksExternal, "external", false, "Also check links to other sites")
+	checkLinksCmd.Flags().IntVar(&checkLinksConcurrency, "concurrency", 8, "Number of external URLs checked in parallel")
+	checkLinksCmd.Flags().DurationVar(&checkLinksTimeout, "timeout", 10*time.Second, "Timeout for each external request")
+	checkLinksCmd.Flags().DurationVar(&checkLinksCacheTTL, "cache-ttl", 24*time.Hour, "How long external results are reused, 0 disables the cache")
+	checkCmd.AddCommand(checkLinksCmd)
+}
+
+// linkProblem 是页面中的一个失效链接
+type linkProblem struct {
+	Line   int
+	URL    string
+	Reason string
+}
+
+// externalLink 是需要检查的外部链接及其出现的位置
+type externalLink struct {
+	Page string
+	Line int
+	URL  string
+}
+
+func runCheckLinks(cmd *cobra.Command, args []string) {
+	site := requireBuiltSite(args[0])
+
+	pages, err := site.htmlPages()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to list pages: %v", err))
+		os.Exit(1)
+	}
+	utils.PrintInfo(fmt.Sprintf("Checking links in %d page(s) of '.deploy/%s'...", len(pages), site.Theme))
+
+	checker := &linkChecker{site: site, resolver: siteResolver(site), ids: make(map[string]map[string]bool)}
+	problems := make(map[string][]linkProblem)
+	var external []externalLink
+	internal := 0
+
+	for _, page := range pages {
+		data, err := site.readPage(page)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to read %s: %v", page, err))
+			os.Exit(1)
+		}
+		links := utils.ParseHTMLLinks(data)
+		checker.ids[page] = links.IDs
+
+		for _, link := range links.Links {
+			if isExternalURL(link.URL) && !checker.isInternal(link.URL, page) {
+				external = append(external, externalLink{Page: page, Line: link.Line, URL: absoluteExternalURL(link.URL)})
+				continue
+			}
+			reason, checked := checker.check(link.URL, page)
+			if checked {
+				internal++
+			}
+			if reason != "" {
+				problems[page] = append(problems[page], linkProblem{Line: link.Line, URL: link.URL, Reason: reason})
+			}
+		}
+	}
+
+	if checkLinksExternal && len(external) > 0 {
+		results := checkExternalLinks(site.ProjectRoot, external)
+		for _, link := range external {
+			if result := results[link.URL]; !result.OK() {
+				problems[link.Page] = append(problems[link.Page], linkProblem{Line: link.Line, URL: link.URL, Reason: result.Describe()})
+			}
+		}
+	}
+
+	broken := printLinkProblems(problems)
+	summary := fmt.Sprintf("%d internal link(s)", internal)
+	if checkLinksExternal {
+		summary += fmt.Sprintf(" and %d external link(s)", len(external))
+	}
+	if broken > 0 {
+		utils.PrintError(fmt.Sprintf("Found %d broken link(s) in %d page(s), checked %s", broken, len(problems), summary))
+		os.Exit(1)
+	}
+	utils.PrintSuccess(fmt.Sprintf("No broken links, checked %s", summary))
+	if !checkLinksExternal && len(external) > 0 {
+		utils.PrintInfo(fmt.Sprintf("%d external link(s) were not checked, use --external to check them", len(external)))
+	}
+}
+
+// siteResolver 返回解析站点中链接的 SiteResolver
+func siteResolver(site *builtSite) utils.SiteResolver {
+	resolver := utils.SiteResolver{BasePath: site.BasePath}
+	if u, err := url.Parse(site.BaseURL); err == nil {
+		resolver.SiteHost = u.Host
+	}
+	return resolver
+}
+
+// linkChecker 检查站内链接
+type linkChecker struct {
+	site     *builtSite
+	resolver utils.SiteResolver
+	ids      map[string]map[string]bool // 页面 -> 页面中的 id
+}
+
+// isInternal 判断绝对 URL 是否指向本站
+func (c *linkChecker) isInternal(ref, page string) bool {
+	_, _, ok := c.resolver.Resolve(ref, path.Dir(page))
+	return ok
+}
+
+// check 检查站内链接，返回失效原因；checked 为 false 表示不是需要检查的链接（mailto: 等）
+func (c *linkChecker) check(ref, page string) (reason string, checked bool) {
+	target, fragment, ok := c.resolver.Resolve(ref, path.Dir(page))
+	if !ok {
+		if !strings.HasPrefix(ref, "#") {
+			if strings.HasPrefix(ref, "/") {
+				return fmt.Sprintf("outside base path '%s'", c.site.BasePath), true
+			}
+			return "", false
+		}
+		// 只有锚点，指向当前页面
+		return c.checkFragment(page, fragment), true
+	}
+
+	file, found := c.site.findFile(target)
+	if !found {
+		return "not found", true
+	}
+	if fragment == "" || assetType(file) != "html" {
+		return "", true
+	}
+	return c.checkFragment(file, fragment), true
+}
+
+// checkFragment 检查页面中是否有锚点对应的 id
+func (c *linkChecker) checkFragment(page, fragment string) string {
+	// 空锚点和 #top 由浏览器处理，总是有效
+	if fragment == "" || strings.EqualFold(fragment, "top") {
+		return ""
+	}
+	ids, ok := c.ids[page]
+	if !ok {
+		data, err := c.site.readPage(page)
+		if err != nil {
+			return fmt.Sprintf("cannot read %s: %v", page, err)
+		}
+		ids = utils.ParseHTMLLinks(data).IDs
+		c.ids[page] = ids
+	}
+	if !ids[fragment] {
+		return fmt.Sprintf("missing anchor #%s", fragment)
+	}
+	return ""
+}
+
+// isExternalURL 判断 URL 是否是 http(s) 或协议相对的绝对 URL
+func isExternalURL(ref string) bool {
+	lower := strings.ToLower(ref)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "//")
+}
+
+// absoluteExternalURL 为协议相对的 URL 补上 https:，并去掉锚点
+func absoluteExternalURL(ref string) string {
+	if strings.HasPrefix(ref, "//") {
+		ref = "https:" + ref
+	}
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// getLinkCachePath 返回外部链接检查结果缓存的路径
+func getLinkCachePath(projectRoot string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "cache", "links.json")
+}
+
+// checkExternalLinks 检查外部链接，缓存中未过期的结果直接使用
+func checkExternalLinks(projectRoot string, links []externalLink) map[string]utils.URLCheckResult {
+	cachePath := getLinkCachePath(projectRoot)
+	results := make(map[string]utils.URLCheckResult)
+	if checkLinksCacheTTL > 0 {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			json.Unmarshal(data, &results)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var pending []string
+	for _, link := range links {
+		if seen[link.URL] {
+			continue
+		}
+		seen[link.URL] = true
+		if cached, ok := results[link.URL]; ok && time.Since(cached.CheckedAt) < checkLinksCacheTTL {
+			continue
+		}
+		pending = append(pending, link.URL)
+	}
+	sort.Strings(pending)
+
+	if len(pending) > 0 {
+		utils.PrintInfo(fmt.Sprintf("Checking %d external URL(s), %d cached...", len(pending), len(seen)-len(pending)))
+		checked := utils.CheckURLs(pending, utils.URLCheckOptions{
+			Concurrency: checkLinksConcurrency,
+			Timeout:     checkLinksTimeout,
+			UserAgent:   "wordma-cli/" + version + " link checker",
+		})
+		for u, result := range checked {
+			results[u] = result
+		}
+	}
+
+	if checkLinksCacheTTL > 0 {
+		// 只缓存可靠的结果，网络错误和 5xx 下次重新检查；过期的结果不再保存
+		cache := make(map[string]utils.URLCheckResult)
+		for u, result := range results {
+			if result.Permanent() && time.Since(result.CheckedAt) < checkLinksCacheTTL {
+				cache[u] = result
+			}
+		}
+		if data, err := json.MarshalIndent(cache, "", "  "); err == nil {
+			if err := utils.CreateDir(filepath.Dir(cachePath)); err == nil {
+				os.WriteFile(cachePath, data, 0644)
+			}
+		}
+	}
+	return results
+}
+
+// printLinkProblems 按页面分组输出失效链接，返回失效链接总数
+func printLinkProblems(problems map[string][]linkProblem) int {
+	pages := make([]string, 0, len(problems))
+	for page := range problems {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+
+	total := 0
+	for _, page := range pages {
+		list := problems[page]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Line < list[j].Line })
+		fmt.Printf("\n%s\n", utils.ColorText(page, "cyan"))
+		for _, problem := range list {
+			fmt.Printf("  %4d  %s  %s\n", problem.Line, problem.URL, utils.ColorText(problem.Reason, "red"))
+		}
+		total += len(list)
+	}
+	if total > 0 {
+		fmt.Println()
+	}
+	return total
+}
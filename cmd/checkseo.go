@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var checkSEOCmd = &cobra.Command{
+	Use:   "seo <theme>",
+	Short: "Audit built pages for common SEO and accessibility problems",
+	Long: `Scan every HTML page in .deploy/<theme> for missing titles and meta descriptions,
+duplicate titles, images without alt text, a missing or incorrect lang attribute
+and multiple <h1> elements. The command fails when a problem reaches --fail-on.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCheckSEO,
+}
+
+var (
+	checkSEOFailOn string
+	checkSEOLang   string
+)
+
+func init() {
+	checkSEOCmd.Flags().StringVar(&checkSEOFailOn, "fail-on", "", "Lowest severity that fails the check: error, warning, info or none (default from seo.failOn, or error)")
+	checkSEOCmd.Flags().StringVar(&checkSEOLang, "lang", "", "Language every page must declare, such as zh-CN (default from seo.lang)")
+	checkCmd.AddCommand(checkSEOCmd)
+}
+
+// 问题的级别，数值越大越严重
+const (
+	severityOff = iota
+	severityInfo
+	severityWarning
+	severityError
+)
+
+var severityNames = map[string]int{"off": severityOff, "info": severityInfo, "warning": severityWarning, "error": severityError}
+
+// seoRules 是全部检查规则及其默认级别
+var seoRules = map[string]int{
+	"missing-title":       severityError,
+	"duplicate-title":     severityWarning,
+	"missing-description": severityWarning,
+	"missing-lang":        severityError,
+	"invalid-lang":        severityError,
+	"multiple-h1":         severityWarning,
+	"missing-alt":         severityError,
+}
+
+// langPattern 匹配 BCP 47 语言标签，如 en、zh-CN、zh-Hant-TW
+var langPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$`)
+
+// seoIssue 是页面中的一个问题
+type seoIssue struct {
+	Rule     string
+	Severity int
+	Line     int
+	Message  string
+}
+
+// seoListedPages 是重复标题的问题中最多列出的其他页面数量
+const seoListedPages = 3
+
+func runCheckSEO(cmd *cobra.Command, args []string) {
+	site := requireBuiltSite(args[0])
+	config := site.Config.seo(site.Theme)
+
+	rules, err := resolveSEORules(config.Rules)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Invalid seo.rules in %s: %v", projectConfigFile, err))
+		os.Exit(1)
+	}
+	failOn := firstNonEmpty(checkSEOFailOn, config.FailOn, "error")
+	failLevel, ok := severityNames[failOn]
+	if failOn == "none" {
+		failLevel, ok = severityError+1, true
+	}
+	if !ok || failOn == "off" {
+		utils.PrintError(fmt.Sprintf("Invalid severity '%s', expected error, warning, info or none", failOn))
+		os.Exit(1)
+	}
+	lang := firstNonEmpty(checkSEOLang, config.Lang)
+
+	pages, err := site.htmlPages()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to list pages: %v", err))
+		os.Exit(1)
+	}
+
+	issues := make(map[string][]seoIssue)
+	add := func(page, rule string, line int, message string) {
+		if rules[rule] != severityOff {
+			issues[page] = append(issues[page], seoIssue{Rule: rule, Severity: rules[rule], Line: line, Message: message})
+		}
+	}
+
+	titles := make(map[string][]string)
+	checked := 0
+	for _, page := range pages {
+		if matchesAny(config.Exclude, page) {
+			continue
+		}
+		data, err := site.readPage(page)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to read %s: %v", page, err))
+			os.Exit(1)
+		}
+		meta := utils.ParsePageMeta(data)
+		// 跳转页面没有内容，不需要检查
+		if meta.Refresh != "" {
+			continue
+		}
+		checked++
+		outline := utils.ParsePageOutline(data)
+
+		switch {
+		case meta.Lang == "":
+			add(page, "missing-lang", 0, "<html> has no lang attribute")
+		case !langPattern.MatchString(meta.Lang):
+			add(page, "invalid-lang", 0, fmt.Sprintf("lang=\"%s\" is not a valid language tag", meta.Lang))
+		case lang != "" && !strings.EqualFold(meta.Lang, lang) && !strings.HasPrefix(strings.ToLower(meta.Lang), strings.ToLower(lang)+"-"):
+			add(page, "invalid-lang", 0, fmt.Sprintf("lang=\"%s\" does not match the site language '%s'", meta.Lang, lang))
+		}
+
+		if meta.Title == "" {
+			add(page, "missing-title", 0, "page has no <title>")
+		} else if !meta.NoIndex() {
+			titles[meta.Title] = append(titles[meta.Title], page)
+		}
+		if meta.Description == "" && !meta.NoIndex() {
+			add(page, "missing-description", 0, "page has no <meta name=\"description\">")
+		}
+
+		if len(outline.H1) > 1 {
+			var lines []string
+			for _, h1 := range outline.H1 {
+				lines = append(lines, fmt.Sprint(h1.Line))
+			}
+			add(page, "multiple-h1", outline.H1[1].Line, fmt.Sprintf("%d <h1> elements (lines %s)", len(outline.H1), strings.Join(lines, ", ")))
+		}
+		for _, img := range outline.MissingAlt {
+			add(page, "missing-alt", img.Line, fmt.Sprintf("<img src=\"%s\"> has no alt attribute", img.Text))
+		}
+	}
+
+	for title, titlePages := range titles {
+		if len(titlePages) < 2 {
+			continue
+		}
+		for _, page := range titlePages {
+			var others []string
+			for _, other := range titlePages {
+				if other != page {
+					others = append(others, other)
+				}
+			}
+			if len(others) > seoListedPages {
+				others = append(others[:seoListedPages], fmt.Sprintf("%d more", len(others)-seoListedPages))
+			}
+			add(page, "duplicate-title", 0, fmt.Sprintf("title \"%s\" is also used by %s", title, strings.Join(others, ", ")))
+		}
+	}
+
+	counts := printSEOIssues(issues)
+	summary := fmt.Sprintf("%d error(s), %d warning(s), %d info in %d page(s)",
+		counts[severityError], counts[severityWarning], counts[severityInfo], checked)
+
+	for level := severityError; level >= failLevel; level-- {
+		if counts[level] > 0 {
+			utils.PrintError("SEO check failed: " + summary)
+			os.Exit(1)
+		}
+	}
+	if counts[severityError]+counts[severityWarning]+counts[severityInfo] > 0 {
+		utils.PrintWarning("SEO check passed with problems: " + summary)
+		return
+	}
+	utils.PrintSuccess(fmt.Sprintf("No problems found in %d page(s)", checked))
+}
+
+// resolveSEORules 将配置中的级别合并到默认规则
+func resolveSEORules(overrides map[string]string) (map[string]int, error) {
+	rules := make(map[string]int)
+	for rule, severity := range seoRules {
+		rules[rule] = severity
+	}
+	for rule, name := range overrides {
+		if _, ok := seoRules[rule]; !ok {
+			return nil, fmt.Errorf("unknown rule '%s'", rule)
+		}
+		severity, ok := severityNames[name]
+		if !ok {
+			return nil, fmt.Errorf("rule '%s' has invalid severity '%s', expected error, warning, info or off", rule, name)
+		}
+		rules[rule] = severity
+	}
+	return rules, nil
+}
+
+// printSEOIssues 按页面分组输出问题，返回每个级别的问题数
+func printSEOIssues(issues map[string][]seoIssue) map[int]int {
+	pages := make([]string, 0, len(issues))
+	for page := range issues {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+
+	colors := map[int]string{severityError: "red", severityWarning: "yellow", severityInfo: "blue"}
+	names := map[int]string{severityError: "error", severityWarning: "warning", severityInfo: "info"}
+
+	counts := make(map[int]int)
+	for _, page := range pages {
+		list := issues[page]
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Severity != list[j].Severity {
+				return list[i].Severity > list[j].Severity
+			}
+			return list[i].Line < list[j].Line
+		})
+
+		fmt.Printf("\n%s\n", utils.ColorText(page, "cyan"))
+		for _, issue := range list {
+			location := ""
+			if issue.Line > 0 {
+				location = fmt.Sprintf("line %d: ", issue.Line)
+			}
+			fmt.Printf("  %s  %-19s %s%s\n", utils.ColorText(fmt.Sprintf("%-7s", names[issue.Severity]), colors[issue.Severity]),
+				issue.Rule, location, issue.Message)
+			counts[issue.Severity]++
+		}
+	}
+	if len(pages) > 0 {
+		fmt.Println()
+	}
+	return counts
+}
+
+// matchesAny 判断路径是否匹配任意一个 glob
+func matchesAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if utils.MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"wordma-cli/utils"
 )
@@ -18,17 +19,21 @@ var deployCmd = &cobra.Command{
 var deployInitCmd = &cobra.Command{
 	Use:   "init <git-url>",
 	Short: "Initialize or recreate the .deploy directory",
-	Long:  "Initialize or recreate the .deploy directory by cloning from the specified git repository.",
+	Long: `Initialize or recreate the .deploy directory by cloning from the specified git repository.
+The predeployinit and postdeployinit hooks from wordma.config.json run before and after the clone.`,
 	Args:  cobra.ExactArgs(1),
 	Run:   runDeployInit,
 }
 
+// deployEnvName 是钩子加载的 .env 环境
+var deployEnvName string
+
 func runDeployInit(cmd *cobra.Command, args []string) {
 	// 获取当前工作目录
 	currentDir, err := os.Getwd()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to get current directory: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查是否在 wordma 项目根目录
@@ -36,21 +41,35 @@ func runDeployInit(cmd *cobra.Command, args []string) {
 		utils.PrintError("Not in a wordma project directory")
 		utils.PrintInfo("Please run this command in the root directory of a wordma project")
 		utils.PrintInfo("(Directory should contain themes/ folder or package.json)")
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查必要的依赖
 	if !utils.CheckCommand("git") {
 		utils.PrintError("Git is required for deploy directory initialization")
 		fmt.Printf("  %s\n", utils.GetInstallInstructions("git"))
-		os.Exit(1)
+		exit(1)
 	}
 
+	lock := lockProject(currentDir, cmd)
+	defer lock.Release()
+
 	deployPath := filepath.Join(currentDir, ".deploy")
 	gitURL := args[0]
 
+	projectConfig, err := loadProjectConfig(currentDir)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		exit(1)
+	}
+	env := requireDotEnv(currentDir, "", deployEnvName)
+	hookEnv := newWordmaEnv(modeDeploy, currentDir, "", env, projectConfig).environ()
+	hookOut, flushHookOut := env.maskOutput(color.Output)
+	defer flushHookOut()
+
 	// 检查 .deploy 目录是否已存在
-	if utils.FileExists(deployPath) {
+	exists := utils.FileExists(deployPath)
+	if exists {
 		utils.PrintWarning(".deploy directory already exists")
 		utils.PrintInfo("Do you want to reinitialize it? This will remove all existing content.")
 
@@ -63,13 +82,22 @@ func runDeployInit(cmd *cobra.Command, args []string) {
 			utils.PrintInfo("Operation cancelled")
 			return
 		}
+	}
 
+	// predeployinit 钩子失败时不修改 .deploy
+	if err := runHook(currentDir, projectConfig.Hooks, "predeployinit", hookEnv, hookOut); err != nil {
+		utils.PrintError(err.Error())
+		flushHookOut()
+		exit(1)
+	}
+
+	if exists {
 		// 删除现有目录
 		utils.PrintInfo("Removing existing .deploy directory...")
 		err = os.RemoveAll(deployPath)
 		if err != nil {
 			utils.PrintError(fmt.Sprintf("Failed to remove existing .deploy directory: %v", err))
-			os.Exit(1)
+			exit(1)
 		}
 	}
 
@@ -79,10 +107,17 @@ func runDeployInit(cmd *cobra.Command, args []string) {
 	err = utils.RunCommandInDir(currentDir, "git", "clone", gitURL, ".deploy")
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to clone repository: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	utils.PrintSuccess("Repository cloned successfully!")
+
+	if err := runHook(currentDir, projectConfig.Hooks, "postdeployinit", hookEnv, hookOut); err != nil {
+		utils.PrintError(err.Error())
+		flushHookOut()
+		exit(1)
+	}
+
 	utils.PrintInfo("Next steps:")
 	fmt.Printf("  1. wordma build <theme-name>  # Build a theme\n")
 	fmt.Printf("  2. cd .deploy                 # Navigate to deploy directory\n")
@@ -117,5 +152,6 @@ func isWordmaProject(dir string) bool {
 }
 
 func init() {
+	deployInitCmd.Flags().StringVar(&deployEnvName, "env", "production", "Environment whose .env files are loaded for hooks")
 	deployCmd.AddCommand(deployInitCmd)
 }
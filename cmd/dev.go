@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"wordma-cli/utils"
 )
@@ -17,6 +19,18 @@ var devCmd = &cobra.Command{
 	Run:   runDev,
 }
 
+var (
+	devSkipValidate bool
+	devEnvName      string
+	devNoMask       bool
+)
+
+func init() {
+	devCmd.Flags().BoolVar(&devSkipValidate, "skip-validate", false, "Skip validating the theme config against its schema")
+	devCmd.Flags().StringVar(&devEnvName, "env", "development", "Environment whose .env files are loaded")
+	devCmd.Flags().BoolVar(&devNoMask, "no-mask", false, "Connect the development server directly to the terminal without masking secrets in its output")
+}
+
 func runDev(cmd *cobra.Command, args []string) {
 	themeName := args[0]
 
@@ -24,14 +38,14 @@ func runDev(cmd *cobra.Command, args []string) {
 	if !utils.CheckCommand("pnpm") {
 		utils.PrintError("pnpm is required for running development server")
 		fmt.Printf("  %s\n", utils.GetInstallInstructions("pnpm"))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取项目根目录
 	projectRoot, err := utils.GetProjectRoot()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 构建主题目录路径
@@ -53,22 +67,83 @@ func runDev(cmd *cobra.Command, args []string) {
 				}
 			}
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查 package.json 是否存在
 	packageJsonPath := filepath.Join(themePath, "package.json")
 	if !utils.FileExists(packageJsonPath) {
 		utils.PrintError(fmt.Sprintf("No package.json found in theme directory '%s'", themePath))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 校验主题配置
+	if !devSkipValidate {
+		checkThemeConfig(projectRoot, themeName, themePath)
+	}
+
+	projectConfig, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		exit(1)
+	}
+
+	env := requireDotEnv(projectRoot, themePath, devEnvName)
+	devEnv := newWordmaEnv(modeDevelopment, projectRoot, themeName, env, projectConfig).environ()
+
+	hookOut, flushHookOut := env.maskOutput(color.Output)
+	err = runHook(projectRoot, projectConfig.Hooks, "predev", devEnv, hookOut)
+	flushHookOut()
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+
+	overlayLock, err := lockThemeOverlay(projectRoot, themeName, cmd.CommandPath(), color.Output)
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
 	}
+	defer overlayLock.Release()
 
 	utils.PrintInfo(fmt.Sprintf("Starting development server for theme '%s'...", themeName))
 	
-	// 执行 pnpm run dev
-	err = utils.RunCommandInDir(themePath, "pnpm", "run", "dev")
+	// 执行 pnpm run dev（期间应用 overrides/<theme>/ 覆盖层），输出中的密钥被替换
+	if devNoMask && len(env.secretValues) > 0 {
+		utils.PrintWarning("Secrets from .env files are not masked in the development server output (--no-mask)")
+	}
+	err = withThemeOverlay(projectRoot, themeName, themePath, color.Output, func() error {
+		stdout, flushStdout := maskDevOutput(env, os.Stdout)
+		defer flushStdout()
+		stderr, flushStderr := maskDevOutput(env, os.Stderr)
+		defer flushStderr()
+		return utils.RunCommandWithOptions(utils.CommandOptions{
+			Dir:    themePath,
+			Env:    devProcessEnv(env, devEnv),
+			Stdout: stdout,
+			Stderr: stderr,
+		}, "pnpm", "run", "dev")
+	})
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to start development server: %v", err))
-		os.Exit(1)
+		exit(1)
+	}
+}
+
+// maskDevOutput 替换开发服务器输出中的密钥，--no-mask 时直接使用终端
+func maskDevOutput(env *dotEnv, f *os.File) (io.Writer, func()) {
+	if devNoMask {
+		return f, func() {}
+	}
+	return env.maskOutput(f)
+}
+
+// devProcessEnv 返回开发服务器的环境变量。替换密钥时输出经过管道，
+// 终端上设置 FORCE_COLOR 让开发服务器保留颜色
+func devProcessEnv(env *dotEnv, devEnv []string) []string {
+	masked := !devNoMask && len(env.secretValues) > 0
+	if masked && utils.IsTerminal(os.Stdout) && os.Getenv("FORCE_COLOR") == "" {
+		return append(devEnv, "FORCE_COLOR=1")
 	}
-}
\ No newline at end of file
+	return devEnv
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// dotEnv 是为某个环境从 .env 文件加载的变量
+type dotEnv struct {
+	Name  string
+	Vars  []utils.EnvVar
+	Files []string // 读取到的文件，相对于项目根目录
+
+	// secretValues 是变量名看起来是密钥的值，包括被进程环境覆盖后实际生效的值
+	secretValues []string
+}
+
+// dotEnvFileNames 返回环境对应的 .env 文件名，后面的文件优先
+func dotEnvFileNames(envName string) []string {
+	return []string{".env", ".env." + envName, ".env." + envName + ".local"}
+}
+
+// loadDotEnv 依次读取主题目录和项目根目录中的 .env、.env.<env> 和 .env.<env>.local，
+// 后读取的文件覆盖先读取的，因此项目的设置优先于主题；已经存在于进程环境中的变量不会被覆盖。
+// themePath 为空时只读取项目根目录
+func loadDotEnv(projectRoot, themePath, envName string) (*dotEnv, error) {
+	env := &dotEnv{Name: envName}
+	values := make(map[string]string)
+
+	for _, dir := range []string{themePath, projectRoot} {
+		if dir == "" {
+			continue
+		}
+		for _, name := range dotEnvFileNames(envName) {
+			path := filepath.Join(dir, name)
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			relPath, _ := filepath.Rel(projectRoot, path)
+			relPath = filepath.ToSlash(relPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", relPath, err)
+			}
+
+			vars, err := utils.ParseDotEnv(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", relPath, err)
+			}
+			for _, v := range vars {
+				values[v.Key] = v.Value
+			}
+			env.Files = append(env.Files, relPath)
+		}
+	}
+
+	for key, value := range values {
+		processValue, inProcess := os.LookupEnv(key)
+		if utils.IsSecretEnvKey(key) {
+			if inProcess {
+				env.secretValues = append(env.secretValues, processValue)
+			} else {
+				env.secretValues = append(env.secretValues, value)
+			}
+		}
+		if !inProcess {
+			env.Vars = append(env.Vars, utils.EnvVar{Key: key, Value: value})
+		}
+	}
+	sort.Slice(env.Vars, func(i, j int) bool { return env.Vars[i].Key < env.Vars[j].Key })
+	return env, nil
+}
+
+// environ 返回 KEY=VALUE 形式的变量
+func (e *dotEnv) environ() []string {
+	environ := make([]string, 0, len(e.Vars))
+	for _, v := range e.Vars {
+		environ = append(environ, v.Key+"="+v.Value)
+	}
+	return environ
+}
+
+// maskOutput 返回替换了密钥的输出，以及结束时需要调用的 flush；没有密钥时原样返回 w
+func (e *dotEnv) maskOutput(w io.Writer) (io.Writer, func()) {
+	if len(e.secretValues) == 0 {
+		return w, func() {}
+	}
+	masked := utils.NewMaskingWriter(w, e.secretValues)
+	return masked, func() { masked.Flush() }
+}
+
+// describe 返回加载结果的说明，没有读取到文件时返回空字符串
+func (e *dotEnv) describe() string {
+	if len(e.Files) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Loaded %d variable(s) for environment '%s' from %s",
+		len(e.Vars), e.Name, strings.Join(e.Files, ", "))
+}
+
+// requireDotEnv 加载 .env 文件，失败时打印错误并退出
+func requireDotEnv(projectRoot, themePath, envName string) *dotEnv {
+	env, err := loadDotEnv(projectRoot, themePath, envName)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to load environment '%s': %v", envName, err))
+		exit(1)
+	}
+	if message := env.describe(); message != "" {
+		utils.PrintInfo(message)
+	}
+	return env
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env [theme-name]",
+	Short: "Print the WORDMA_* variables passed to theme scripts",
+	Long: `Print the WORDMA_* environment variables that dev, build and install pass to pnpm.
+Without a theme name only the project-wide variables are printed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runEnv,
+}
+
+var (
+	envMode    string
+	envEnvName string
+	envJSON    bool
+)
+
+func init() {
+	envCmd.Flags().StringVar(&envMode, "mode", modeProduction, "Mode to print the variables for (development or production)")
+	envCmd.Flags().StringVar(&envEnvName, "env", "", "Environment whose .env files are loaded (default: development or production, following --mode)")
+	envCmd.Flags().BoolVar(&envJSON, "json", false, "Print the variables as a JSON object")
+	rootCmd.AddCommand(envCmd)
+}
+
+// WORDMA_MODE 的取值
+const (
+	modeDevelopment = "development"
+	modeProduction  = "production"
+	modeInstall     = "install"
+	modeDeploy      = "deploy"
+)
+
+// wordmaEnv 是传给主题脚本和钩子的 WORDMA_* 环境变量
+type wordmaEnv struct {
+	Mode        string
+	ProjectRoot string
+	Theme       string
+	ThemeDir    string
+	OutDir      string
+	DeployDir   string
+	BaseURL     string
+	BasePath    string
+	Env         *dotEnv
+}
+
+// newWordmaEnv 创建项目或主题（themeName 不为空时）的环境变量
+func newWordmaEnv(mode, projectRoot, themeName string, env *dotEnv, projectConfig *ProjectConfig) *wordmaEnv {
+	e := &wordmaEnv{
+		Mode:        mode,
+		ProjectRoot: projectRoot,
+		DeployDir:   filepath.Join(projectRoot, ".deploy"),
+		Env:         env,
+	}
+	if themeName != "" {
+		e.Theme = themeName
+		e.ThemeDir = filepath.Join(projectRoot, "themes", themeName)
+		e.DeployDir = getLiveDir(projectRoot, themeName)
+	}
+	e.BaseURL = resolveBaseURL(projectConfig, themeName, env)
+	return e
+}
+
+// resolveBaseURL 确定站点地址：环境中的 WORDMA_BASE_URL（包括 .env 文件）优先，
+// 其次是项目配置的 themes.<theme>.baseUrl 和 baseUrl
+func resolveBaseURL(projectConfig *ProjectConfig, themeName string, env *dotEnv) string {
+	if value, ok := os.LookupEnv("WORDMA_BASE_URL"); ok {
+		return value
+	}
+	if env != nil {
+		for _, v := range env.Vars {
+			if v.Key == "WORDMA_BASE_URL" {
+				return v.Value
+			}
+		}
+	}
+	if projectConfig == nil {
+		return ""
+	}
+	if themeName != "" {
+		if baseURL := projectConfig.theme(themeName).BaseURL; baseURL != "" {
+			return baseURL
+		}
+	}
+	return projectConfig.BaseURL
+}
+
+// vars 按文档中的顺序返回 WORDMA_* 变量，没有值的变量不包含在内
+func (e *wordmaEnv) vars() []utils.EnvVar {
+	envName := ""
+	if e.Env != nil {
+		envName = e.Env.Name
+	}
+
+	var vars []utils.EnvVar
+	for _, v := range []utils.EnvVar{
+		{Key: "WORDMA_CLI_VERSION", Value: version},
+		{Key: "WORDMA_MODE", Value: e.Mode},
+		{Key: "WORDMA_ENV", Value: envName},
+		{Key: "WORDMA_PROJECT_ROOT", Value: e.ProjectRoot},
+		{Key: "WORDMA_THEME", Value: e.Theme},
+		{Key: "WORDMA_THEME_DIR", Value: e.ThemeDir},
+		{Key: "WORDMA_OUT_DIR", Value: e.OutDir},
+		{Key: "WORDMA_DEPLOY_DIR", Value: e.DeployDir},
+		{Key: "WORDMA_BASE_URL", Value: e.BaseURL},
+		{Key: "WORDMA_BASE_PATH", Value: e.BasePath},
+	} {
+		if v.Value != "" {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// environ 返回 .env 中的变量和 WORDMA_* 变量（KEY=VALUE），WORDMA_* 变量不会被 .env 覆盖；
+// extra 中的变量追加在最后
+func (e *wordmaEnv) environ(extra ...string) []string {
+	var environ []string
+	if e.Env != nil {
+		environ = e.Env.environ()
+	}
+	for _, v := range e.vars() {
+		environ = append(environ, v.Key+"="+v.Value)
+	}
+	return append(environ, extra...)
+}
+
+func runEnv(cmd *cobra.Command, args []string) {
+	if envMode != modeDevelopment && envMode != modeProduction {
+		utils.PrintError(fmt.Sprintf("Invalid mode '%s', expected '%s' or '%s'", envMode, modeDevelopment, modeProduction))
+		os.Exit(1)
+	}
+
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		os.Exit(1)
+	}
+
+	projectConfig, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		os.Exit(1)
+	}
+
+	envName := envEnvName
+	if envName == "" {
+		envName = envMode
+	}
+
+	themeName, themePath := "", ""
+	if len(args) > 0 {
+		themeName = args[0]
+		themePath = filepath.Join(projectRoot, "themes", themeName)
+		if !utils.FileExists(themePath) {
+			utils.PrintError(fmt.Sprintf("Theme '%s' not found in themes directory", themeName))
+			os.Exit(1)
+		}
+	}
+
+	env, err := loadDotEnv(projectRoot, themePath, envName)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to load environment '%s': %v", envName, err))
+		os.Exit(1)
+	}
+
+	e := newWordmaEnv(envMode, projectRoot, themeName, env, projectConfig)
+	if themeName != "" && envMode == modeProduction {
+		manifest, err := loadThemeManifest(themePath)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to read theme manifest: %v", err))
+			os.Exit(1)
+		}
+		e.OutDir, _, err = resolveBuildOutDir(projectRoot, themeName, themePath, manifest, projectConfig)
+		if err != nil {
+			utils.PrintError(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if envJSON {
+		values := make(map[string]string)
+		for _, v := range e.vars() {
+			values[v.Key] = v.Value
+		}
+		data, _ := json.MarshalIndent(values, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	for _, v := range e.vars() {
+		fmt.Printf("%s=%s\n", v.Key, v.Value)
+	}
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"wordma-cli/utils"
+)
+
+// defaultFeedLimit 是订阅中默认包含的文章数
+const defaultFeedLimit = 20
+
+// feedSummaryLength 是页面没有描述时从正文截取的摘要长度
+const feedSummaryLength = 200
+
+// feedFormat 是一种订阅格式及其文件名
+type feedFormat struct {
+	Name   string
+	File   string
+	Render func(feed utils.Feed, selfURL string) ([]byte, error)
+}
+
+// feedFormats 是支持的订阅格式，按生成顺序排列
+var feedFormats = []feedFormat{
+	{"rss", "feed.xml", utils.RenderRSS},
+	{"atom", "atom.xml", utils.RenderAtom},
+	{"json", "feed.json", utils.RenderJSONFeed},
+}
+
+// feedSettings 是一次构建使用的订阅设置
+type feedSettings struct {
+	Include     []string
+	Exclude     []string
+	Limit       int
+	Formats     []string
+	Title       string
+	Description string
+	Author      string
+}
+
+// resolveFeeds 根据项目配置和 --feeds 确定订阅设置，不生成时返回 nil
+func resolveFeeds(config *FeedConfig, flag, flagSet bool) *feedSettings {
+	if config == nil {
+		config = &FeedConfig{}
+		if !flagSet {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+
+	settings := &feedSettings{
+		Include:     config.Include,
+		Exclude:     append(append([]string{}, defaultSitemapExclude...), config.Exclude...),
+		Limit:       config.Limit,
+		Formats:     config.Formats,
+		Title:       config.Title,
+		Description: config.Description,
+		Author:      config.Author,
+	}
+	if settings.Limit <= 0 {
+		settings.Limit = defaultFeedLimit
+	}
+	if len(settings.Formats) == 0 {
+		for _, format := range feedFormats {
+			settings.Formats = append(settings.Formats, format.Name)
+		}
+	}
+	return settings
+}
+
+// generateFeeds 根据输出中带发布时间的页面生成 feed.xml、atom.xml 和 feed.json，主题已生成的文件保持不变
+func (b *themeBuild) generateFeeds(outDir string) error {
+	formats, err := b.selectedFeedFormats()
+	if err != nil {
+		return err
+	}
+	root, err := siteRootURL(b.WordmaEnv.BaseURL, b.BasePath)
+	if err != nil {
+		return fmt.Errorf("cannot generate feeds: %v", err)
+	}
+
+	feed, undated, err := b.collectFeed(outDir, root)
+	if err != nil {
+		return fmt.Errorf("failed to collect posts for feeds: %v", err)
+	}
+
+	var written []string
+	for _, format := range formats {
+		file := filepath.Join(outDir, format.File)
+		if utils.FileExists(file) {
+			utils.FprintInfo(b.Out, fmt.Sprintf("Build output already contains %s, leaving it unchanged", format.File))
+			continue
+		}
+		data, err := format.Render(feed, root.String()+format.File)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %v", format.File, err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", format.File, err)
+		}
+		written = append(written, format.File)
+	}
+
+	if len(written) > 0 {
+		utils.FprintInfo(b.Out, fmt.Sprintf("Feeds with %d post(s) written to %s", len(feed.Items), strings.Join(written, ", ")))
+	}
+	if undated > 0 {
+		utils.FprintWarning(b.Out, fmt.Sprintf("%d included page(s) have no article:published_time or datePublished and were left out of the feeds", undated))
+	}
+	return nil
+}
+
+// selectedFeedFormats 返回设置中的订阅格式，格式未知时返回错误
+func (b *themeBuild) selectedFeedFormats() ([]feedFormat, error) {
+	var formats []feedFormat
+	for _, name := range b.Feeds.Formats {
+		found := false
+		for _, format := range feedFormats {
+			if strings.EqualFold(name, format.Name) {
+				formats = append(formats, format)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown feed format '%s', expected rss, atom or json", name)
+		}
+	}
+	return formats, nil
+}
+
+// collectFeed 读取输出中的文章，按发布时间从新到旧排列并截取前 Limit 篇；
+// 返回的数字是匹配 include 但没有发布时间的页面数（未设置 include 时为 0）
+func (b *themeBuild) collectFeed(outDir string, root *url.URL) (utils.Feed, int, error) {
+	settings := b.Feeds
+	feed := utils.Feed{
+		Title:       settings.Title,
+		Description: settings.Description,
+		Author:      settings.Author,
+		HomeURL:     root.String(),
+	}
+
+	// 订阅的标题、描述和语言默认取自首页
+	if data, err := os.ReadFile(filepath.Join(outDir, "index.html")); err == nil {
+		meta := utils.ParsePageMeta(data)
+		feed.Title = firstNonEmpty(feed.Title, meta.Title)
+		feed.Description = firstNonEmpty(feed.Description, meta.Description)
+		feed.Language = meta.Lang
+	}
+	feed.Title = firstNonEmpty(feed.Title, b.Manifest.Title())
+
+	undated := 0
+	err := filepath.Walk(outDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || assetType(path) != "html" {
+			return nil
+		}
+		relPath, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if matchesAny(settings.Exclude, relPath) || (len(settings.Include) > 0 && !matchesAny(settings.Include, relPath)) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		meta := utils.ParsePageMeta(data)
+		if meta.NoIndex() || meta.Refresh != "" {
+			return nil
+		}
+		published, ok := utils.ParseLastMod(meta.Published)
+		if !ok {
+			if len(settings.Include) > 0 {
+				undated++
+			}
+			return nil
+		}
+		updated, ok := utils.ParseLastMod(meta.Modified)
+		if !ok || updated.Before(published) {
+			updated = published
+		}
+
+		item := utils.FeedItem{
+			URL:       root.ResolveReference(&url.URL{Path: pageURLPath(relPath)}).String(),
+			Title:     meta.Title,
+			Summary:   meta.Description,
+			Published: published,
+			Updated:   updated,
+		}
+		if meta.Canonical != "" {
+			if canonical, err := root.Parse(meta.Canonical); err == nil && canonical.Host == root.Host {
+				item.URL = canonical.String()
+			}
+		}
+		if item.Title == "" || item.Summary == "" {
+			doc, err := utils.ExtractSearchDocument(data, feedSummaryLength)
+			if err != nil {
+				return fmt.Errorf("%s: %v", relPath, err)
+			}
+			if item.Title == "" && len(doc.Headings) > 0 {
+				item.Title = doc.Headings[0]
+			}
+			item.Summary = firstNonEmpty(item.Summary, doc.Text)
+		}
+		feed.Items = append(feed.Items, item)
+		return nil
+	})
+	if err != nil {
+		return feed, undated, err
+	}
+
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		x, y := feed.Items[i], feed.Items[j]
+		if !x.Published.Equal(y.Published) {
+			return x.Published.After(y.Published)
+		}
+		return x.URL < y.URL
+	})
+	if len(feed.Items) > settings.Limit {
+		feed.Items = feed.Items[:settings.Limit]
+	}
+
+	for _, item := range feed.Items {
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+	return feed, undated, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"wordma-cli/utils"
+)
+
+// fingerprintVersion 在指纹的计算方式变化时递增，使旧指纹全部失效
+const fingerprintVersion = "2"
+
+// fingerprintSkipDirs 是计算指纹时忽略的目录名：版本库、依赖，以及构建工具写入的缓存。
+// 缓存在每次构建时都会变化，计入指纹会让没有变化的第二次构建也无法跳过
+var fingerprintSkipDirs = map[string]bool{
+	".git":          true,
+	"node_modules":  true,
+	".astro":        true,
+	".vite":         true,
+	".cache":        true,
+	".next":         true,
+	".nuxt":         true,
+	".svelte-kit":   true,
+	".turbo":        true,
+	".parcel-cache": true,
+}
+
+// computeBuildFingerprint 计算决定构建结果的全部输入的指纹：主题源码（含主题配置）、
+// overrides/<theme>（覆盖后的有效配置）、项目内容、wordma.config.json、锁文件和 .env 变量。
+// 项目根目录下除 themes、overrides、.deploy 和 .wordma 外的文件都视为站点内容
+func computeBuildFingerprint(b *themeBuild) (string, error) {
+	fp := utils.NewFingerprint()
+	fp.AddString("version", fingerprintVersion)
+	fp.AddString("theme", b.Name)
+	for _, v := range b.buildEnv() {
+		fp.AddString("env", v)
+	}
+	// 预压缩、哈希化、sitemap、订阅、搜索索引、integrity 和托管配置决定了输出的文件
+	if b.Precompress != nil {
+		fp.AddString("precompress", fmt.Sprintf("%+v", *b.Precompress))
+	}
+	if b.HashAssets != nil {
+		fp.AddString("hash-assets", fmt.Sprintf("%+v", *b.HashAssets))
+	}
+	if b.Sitemap != nil {
+		fp.AddString("sitemap", fmt.Sprintf("%+v", *b.Sitemap))
+	}
+	if b.Feeds != nil {
+		fp.AddString("feeds", fmt.Sprintf("%+v", *b.Feeds))
+	}
+	if b.Search != nil {
+		fp.AddString("search", fmt.Sprintf("%+v", *b.Search))
+	}
+	if b.SRI != nil {
+		fp.AddString("sri", fmt.Sprintf("%+v", *b.SRI))
+	}
+	if b.Hosting != nil {
+		fp.AddString("hosting", fmt.Sprintf("%+v", *b.Hosting))
+	}
+
+	skip := func(path string, info os.FileInfo) bool {
+		if info.IsDir() && fingerprintSkipDirs[info.Name()] {
+			return true
+		}
+		// 声明在主题或项目内的输出目录是构建结果，不是输入
+		return path == b.OutDir
+	}
+
+	if err := fp.AddTree(b.ThemePath, "themes/"+b.Name, skip); err != nil {
+		return "", err
+	}
+	if err := fp.AddTree(getOverridesDir(b.ProjectRoot, b.Name), "overrides/"+b.Name, skip); err != nil {
+		return "", err
+	}
+
+	projectSkip := func(path string, info os.FileInfo) bool {
+		if filepath.Dir(path) == b.ProjectRoot {
+			switch info.Name() {
+			case "themes", "overrides", ".deploy", filepath.Base(utils.GetStateDir(b.ProjectRoot)):
+				return true
+			}
+		}
+		return skip(path, info)
+	}
+	if err := fp.AddTree(b.ProjectRoot, "", projectSkip); err != nil {
+		return "", err
+	}
+
+	return fp.Sum(), nil
+}
+
+// isBuildUpToDate 判断当前上线的版本是否由相同的输入构建
+func isBuildUpToDate(projectRoot, themeName, fingerprint string) bool {
+	index, err := loadReleaseIndex(projectRoot, themeName)
+	if err != nil {
+		return false
+	}
+	current := index.find(index.Current)
+	if current < 0 || index.Releases[current].Fingerprint != fingerprint {
+		return false
+	}
+	return utils.FileExists(getLiveDir(projectRoot, themeName))
+}
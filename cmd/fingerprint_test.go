@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildFingerprintIgnoresCaches(t *testing.T) {
+	projectRoot := t.TempDir()
+	themePath := filepath.Join(projectRoot, "themes", "t")
+	writeRepoFiles(t, projectRoot, map[string]string{
+		"themes/t/package.json":    `{"name": "t"}`,
+		"themes/t/src/index.astro": "<h1>Hi</h1>",
+		"content/post.md":          "# Post",
+	})
+
+	b := &themeBuild{
+		Name:        "t",
+		ProjectRoot: projectRoot,
+		ThemePath:   themePath,
+		OutDir:      filepath.Join(projectRoot, ".deploy", ".temp-t"),
+		WordmaEnv:   newWordmaEnv(modeProduction, projectRoot, "t", &dotEnv{}, nil),
+	}
+	first, err := computeBuildFingerprint(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 上一次构建写入的缓存和输出不影响指纹
+	writeRepoFiles(t, projectRoot, map[string]string{
+		"themes/t/.astro/types.d.ts":            "declare module 'x';",
+		"themes/t/node_modules/.vite/deps.json": "{}",
+		"themes/t/.cache/data.json":             "{}",
+		"themes/t/.turbo/log":                   "build",
+		"themes/t/.next/cache/x":                "x",
+		".deploy/.temp-t/index.html":            "<h1>Hi</h1>",
+		".wordma/releases/t/releases.json":      "{}",
+	})
+	second, err := computeBuildFingerprint(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected build caches not to change the fingerprint")
+	}
+
+	// 没有变化的第二次构建被跳过
+	index := &releaseIndex{Current: "r1", Releases: []releaseInfo{{ID: "r1", Fingerprint: first}}}
+	if err := saveReleaseIndex(projectRoot, "t", index); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(getLiveDir(projectRoot, "t"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !isBuildUpToDate(projectRoot, "t", second) {
+		t.Error("expected an unchanged build to be up to date")
+	}
+
+	// 修改源码或内容后需要重新构建
+	writeRepoFiles(t, projectRoot, map[string]string{"content/post.md": "# Post, edited"})
+	third, err := computeBuildFingerprint(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isBuildUpToDate(projectRoot, "t", third) {
+		t.Error("expected a content change to require a rebuild")
+	}
+}
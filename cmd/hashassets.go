@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// defaultAssetManifest 是默认的资源映射文件名
+const defaultAssetManifest = "manifest.json"
+
+// defaultHashAssetExtensions 是默认哈希化的文件类型
+var defaultHashAssetExtensions = []string{
+	".css", ".js", ".mjs",
+	".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif",
+	".woff", ".woff2", ".ttf", ".otf", ".eot",
+}
+
+// defaultHashAssetExclude 是需要固定文件名、默认不哈希化的文件
+var defaultHashAssetExclude = []string{"sw.js", "service-worker.js"}
+
+// hashAssetsSettings 是一次构建使用的资源文件名哈希化设置
+type hashAssetsSettings struct {
+	Extensions []string
+	Exclude    []string
+	Manifest   string
+}
+
+// resolveHashAssets 根据项目配置和 --hash-assets 确定哈希化设置，不哈希化时返回 nil
+func resolveHashAssets(config *HashAssetsConfig, flag, flagSet bool) *hashAssetsSettings {
+	if config == nil {
+		config = &HashAssetsConfig{}
+		if !flagSet {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+
+	settings := &hashAssetsSettings{
+		Extensions: defaultHashAssetExtensions,
+		Exclude:    append(append([]string{}, defaultHashAssetExclude...), config.Exclude...),
+		Manifest:   defaultAssetManifest,
+	}
+	if len(config.Extensions) > 0 {
+		settings.Extensions = config.Extensions
+	}
+	if config.Manifest != "" {
+		settings.Manifest = filepath.ToSlash(filepath.Clean(config.Manifest))
+	}
+	return settings
+}
+
+// hashAssets 将输出中的静态资源重命名为带内容哈希的文件名，重写引用并写入映射文件
+func (b *themeBuild) hashAssets(outDir string) error {
+	settings := b.HashAssets
+	if strings.HasPrefix(settings.Manifest, "../") || filepath.IsAbs(settings.Manifest) {
+		return fmt.Errorf("hashAssets.manifest must be a path inside the output directory, got '%s'", settings.Manifest)
+	}
+	manifestPath := filepath.Join(outDir, filepath.FromSlash(settings.Manifest))
+	if utils.FileExists(manifestPath) {
+		return fmt.Errorf("build output already contains '%s', set hashAssets.manifest in %s to another path",
+			settings.Manifest, projectConfigFile)
+	}
+
+	opts := utils.AssetHashOptions{
+		Extensions: settings.Extensions,
+		Exclude:    settings.Exclude,
+		BasePath:   b.BasePath,
+	}
+	if u, err := url.Parse(b.WordmaEnv.BaseURL); err == nil {
+		opts.SiteHost = u.Host
+	}
+
+	result, err := utils.HashAssets(outDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to hash asset names: %v", err)
+	}
+
+	data, err := json.MarshalIndent(result.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.CreateDir(filepath.Dir(manifestPath)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write asset manifest: %v", err)
+	}
+
+	utils.FprintInfo(b.Out, fmt.Sprintf("Hashed %d asset name(s) and rewrote references in %d file(s), mapping saved to '%s'",
+		len(result.Manifest), result.Rewritten, settings.Manifest))
+	if len(result.Cyclic) > 0 {
+		utils.FprintWarning(b.Out, fmt.Sprintf("%d file(s) reference each other and keep their names: %s",
+			len(result.Cyclic), strings.Join(result.Cyclic, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// hookNames 是项目配置中可以声明的钩子。CLI 不负责发布站点，
+// 部署相关的钩子只在 deploy init 克隆部署仓库前后执行，名称中因此带有 init
+var hookNames = []string{"prebuild", "postbuild", "predev", "predeployinit", "postdeployinit"}
+
+// HookCommands 是一个钩子要执行的命令，配置中可以写一个字符串或字符串数组
+type HookCommands []string
+
+// UnmarshalJSON 解析字符串或字符串数组
+func (h *HookCommands) UnmarshalJSON(data []byte) error {
+	var command string
+	if err := json.Unmarshal(data, &command); err == nil {
+		*h = HookCommands{command}
+		return nil
+	}
+
+	var commands []string
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return fmt.Errorf("hook must be a command string or an array of command strings")
+	}
+	*h = commands
+	return nil
+}
+
+// validateHooks 检查钩子名称
+func validateHooks(hooks map[string]HookCommands) error {
+	for name := range hooks {
+		known := false
+		for _, hookName := range hookNames {
+			known = known || name == hookName
+		}
+		if !known {
+			return fmt.Errorf("unknown hook '%s', expected one of: %s", name, strings.Join(hookNames, ", "))
+		}
+	}
+	return nil
+}
+
+// runHook 在项目根目录依次执行钩子的命令，任何一个命令失败时停止并返回错误；
+// env 是追加到当前环境的变量
+func runHook(projectRoot string, hooks map[string]HookCommands, name string, env []string, out io.Writer) error {
+	commands := hooks[name]
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env = append(env, "WORDMA_HOOK="+name)
+	for _, command := range commands {
+		utils.FprintInfo(out, fmt.Sprintf("Running %s hook: %s", name, command))
+		shell, args := utils.ShellCommand(command)
+		err := utils.RunCommandWithOptions(utils.CommandOptions{
+			Dir:    projectRoot,
+			Env:    env,
+			Stdout: out,
+			Stderr: out,
+		}, shell, args...)
+		if err != nil {
+			return fmt.Errorf("%s hook '%s' failed: %v", name, command, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// hostingTargets 是支持的部署目标
+var hostingTargets = []string{"netlify", "cloudflare", "nginx", "github-pages"}
+
+// getNginxConfigPath 返回为 nginx 生成的配置片段的路径，位于上线目录旁边而不是站点根目录中
+func getNginxConfigPath(projectRoot, themeName string) string {
+	return filepath.Join(projectRoot, ".deploy", themeName+".nginx.conf")
+}
+
+// hostingSettings 是一次构建使用的托管配置
+type hostingSettings struct {
+	Target    string
+	Redirects []utils.Redirect
+	Headers   []utils.HeaderRule
+	CSP       bool
+}
+
+// resolveHosting 根据项目配置和 --hosting 确定托管配置，不生成时返回 nil；
+// --hosting 代替配置中的部署目标，为 none 时不生成
+func resolveHosting(config *HostingConfig, target string, targetSet bool) (*hostingSettings, error) {
+	if config == nil {
+		if !targetSet {
+			return nil, nil
+		}
+		config = &HostingConfig{}
+	}
+	if targetSet {
+		if target == "none" {
+			return nil, nil
+		}
+		config = &HostingConfig{Target: target, Redirects: config.Redirects, Headers: config.Headers, CSP: config.CSP}
+	}
+
+	settings := &hostingSettings{Target: strings.ToLower(config.Target), CSP: config.CSP}
+	if settings.Target == "" {
+		return nil, fmt.Errorf("hosting.target is not set, expected one of %s", strings.Join(hostingTargets, ", "))
+	}
+	if !utils.Contains(hostingTargets, settings.Target) {
+		return nil, fmt.Errorf("unknown hosting target '%s', expected one of %s", config.Target, strings.Join(hostingTargets, ", "))
+	}
+
+	for i, rule := range config.Redirects {
+		redirect := utils.Redirect{From: strings.TrimSpace(rule.From), To: strings.TrimSpace(rule.To), Status: rule.Status}
+		if redirect.Status == 0 {
+			redirect.Status = 301
+		}
+		switch {
+		case !strings.HasPrefix(redirect.From, "/") || strings.Contains(redirect.From+"/", "/../"):
+			return nil, fmt.Errorf("hosting.redirects[%d]: from must be a path starting with /, got '%s'", i, rule.From)
+		case redirect.To == "":
+			return nil, fmt.Errorf("hosting.redirects[%d]: to is required", i)
+		case strings.Contains(strings.TrimSuffix(redirect.From, "*"), "*"):
+			return nil, fmt.Errorf("hosting.redirects[%d]: * is only supported at the end of from", i)
+		case settings.Target == "github-pages" && strings.HasSuffix(redirect.From, "*"):
+			return nil, fmt.Errorf("hosting.redirects[%d]: GitHub Pages cannot redirect '%s', wildcards need a server", i, redirect.From)
+		}
+		switch redirect.Status {
+		case 301, 302, 303, 307, 308:
+		default:
+			return nil, fmt.Errorf("hosting.redirects[%d]: unsupported status %d, expected 301, 302, 303, 307 or 308", i, redirect.Status)
+		}
+		settings.Redirects = append(settings.Redirects, redirect)
+	}
+
+	for i, rule := range config.Headers {
+		if !strings.HasPrefix(rule.Path, "/") {
+			return nil, fmt.Errorf("hosting.headers[%d]: path must start with /, got '%s'", i, rule.Path)
+		}
+		if len(rule.Values) == 0 {
+			return nil, fmt.Errorf("hosting.headers[%d]: values is empty", i)
+		}
+		for name, value := range rule.Values {
+			if name == "" || strings.ContainsAny(name, " \t:\r\n") || strings.ContainsAny(value, "\r\n") {
+				return nil, fmt.Errorf("hosting.headers[%d]: invalid header '%s'", i, name)
+			}
+		}
+		settings.Headers = append(settings.Headers, utils.HeaderRule{Path: rule.Path, Values: rule.Values})
+	}
+	return settings, nil
+}
+
+// writeHostingConfig 按部署目标把重定向和响应头写入输出目录；nginx 的配置片段在上线后由 saveNginxConfig 写入
+func (b *themeBuild) writeHostingConfig(outDir string) error {
+	settings := b.Hosting
+	redirects, headers := b.hostingRules()
+
+	switch settings.Target {
+	case "netlify", "cloudflare":
+		var written []string
+		if len(redirects) > 0 {
+			if err := appendHostingFile(filepath.Join(outDir, "_redirects"), utils.RenderNetlifyRedirects(redirects)); err != nil {
+				return err
+			}
+			written = append(written, fmt.Sprintf("_redirects (%d rule(s))", len(redirects)))
+		}
+		if len(headers) > 0 {
+			if err := appendHostingFile(filepath.Join(outDir, "_headers"), utils.RenderNetlifyHeaders(headers)); err != nil {
+				return err
+			}
+			written = append(written, fmt.Sprintf("_headers (%d rule(s))", len(headers)))
+		}
+		if len(written) > 0 {
+			utils.FprintInfo(b.Out, fmt.Sprintf("Hosting config for %s written to %s", settings.Target, strings.Join(written, ", ")))
+		}
+		if b.BasePath != "/" {
+			utils.FprintWarning(b.Out, fmt.Sprintf("_redirects and _headers are only read at the root of the published site, copy them there when the site is served from '%s'", b.BasePath))
+		}
+
+	case "nginx":
+		// 配置片段不能放进站点目录，否则会被当作静态文件公开；上线后再写入 .deploy/<theme>.nginx.conf
+		content := fmt.Sprintf("# Generated by wordma build for theme '%s', include it in the server block\n\n", b.Name)
+		b.NginxConfig = append([]byte(content), utils.RenderNginx(redirects, headers)...)
+
+	case "github-pages":
+		return b.writeRedirectStubs(outDir, redirects, headers)
+	}
+	return nil
+}
+
+// getReleaseNginxConfigPath 返回与版本一起保存的 nginx 配置片段，不放在版本目录中，避免被当作站点文件
+func getReleaseNginxConfigPath(projectRoot, themeName, id string) string {
+	return filepath.Join(getReleasesDir(projectRoot, themeName), id+".nginx.conf")
+}
+
+// saveNginxConfig 将 nginx 配置片段与新版本一起保存，上线或回滚时由 installNginxConfig 写入 .deploy
+func (b *themeBuild) saveNginxConfig(releaseID string) error {
+	if err := os.WriteFile(getReleaseNginxConfigPath(b.ProjectRoot, b.Name, releaseID), b.NginxConfig, 0644); err != nil {
+		return fmt.Errorf("failed to write nginx config: %v", err)
+	}
+	return nil
+}
+
+// installNginxConfig 将版本的 nginx 配置片段写入 .deploy/<theme>.nginx.conf；
+// 版本没有配置片段时删除旧的，保证配置与上线的站点来自同一次构建
+func installNginxConfig(projectRoot, themeName, id string) error {
+	file := getNginxConfigPath(projectRoot, themeName)
+	source := getReleaseNginxConfigPath(projectRoot, themeName, id)
+	if !utils.FileExists(source) {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return utils.CopyFile(source, file)
+}
+
+// hostingRules 返回加上基础路径的重定向和响应头，需要时加入 CSP 建议
+func (b *themeBuild) hostingRules() ([]utils.Redirect, []utils.HeaderRule) {
+	prefix := strings.TrimSuffix(b.BasePath, "/")
+	withBase := func(p string) string {
+		if strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "//") {
+			return prefix + p
+		}
+		return p
+	}
+
+	var redirects []utils.Redirect
+	for _, r := range b.Hosting.Redirects {
+		redirects = append(redirects, utils.Redirect{From: withBase(r.From), To: withBase(r.To), Status: r.Status})
+	}
+	// nginx 配置片段 include 在站点自己的 server 块中，/* 的响应头直接写在 server 级别
+	headerPath := func(p string) string {
+		if p == "/*" && b.Hosting.Target == "nginx" {
+			return p
+		}
+		return withBase(p)
+	}
+	var headers []utils.HeaderRule
+	for _, rule := range b.Hosting.Headers {
+		headers = append(headers, utils.HeaderRule{Path: headerPath(rule.Path), Values: rule.Values})
+	}
+
+	if b.Hosting.CSP {
+		if b.CSP == "" {
+			utils.FprintWarning(b.Out, "hosting.csp is set but no Content-Security-Policy was generated, enable sri (or use --sri) to generate one")
+		} else {
+			headers = append(headers, utils.HeaderRule{Path: headerPath("/*"), Values: map[string]string{"Content-Security-Policy": b.CSP}})
+		}
+	}
+	return redirects, headers
+}
+
+// writeRedirectStubs 为每条重定向在原路径写入跳转页面；GitHub Pages 不支持自定义响应头
+func (b *themeBuild) writeRedirectStubs(outDir string, redirects []utils.Redirect, headers []utils.HeaderRule) error {
+	prefix := strings.TrimSuffix(b.BasePath, "/")
+	written := 0
+	for _, r := range redirects {
+		relPath := strings.TrimPrefix(strings.TrimPrefix(r.From, prefix), "/")
+		switch {
+		case relPath == "" || strings.HasSuffix(relPath, "/"):
+			relPath += "index.html"
+		case path.Ext(relPath) == "":
+			relPath += "/index.html"
+		case assetType(relPath) != "html":
+			utils.FprintWarning(b.Out, fmt.Sprintf("GitHub Pages can only redirect pages, skipping '%s'", r.From))
+			continue
+		}
+
+		file := filepath.Join(outDir, filepath.FromSlash(relPath))
+		if utils.FileExists(file) {
+			utils.FprintWarning(b.Out, fmt.Sprintf("Build output already contains '%s', not replacing it with a redirect to '%s'", relPath, r.To))
+			continue
+		}
+		if err := utils.CreateDir(filepath.Dir(file)); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, utils.RenderRedirectStub(r.To), 0644); err != nil {
+			return fmt.Errorf("failed to write redirect page '%s': %v", relPath, err)
+		}
+		written++
+	}
+
+	if written > 0 {
+		utils.FprintInfo(b.Out, fmt.Sprintf("Wrote %d redirect page(s) for GitHub Pages", written))
+	}
+	if len(headers) > 0 {
+		utils.FprintWarning(b.Out, fmt.Sprintf("GitHub Pages does not support custom headers, %d header rule(s) were not written", len(headers)))
+	}
+	return nil
+}
+
+// appendHostingFile 写入托管配置文件；主题已生成同名文件时把规则追加在后面，主题的规则优先
+func appendHostingFile(file string, content []byte) error {
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 {
+		if existing[len(existing)-1] != '\n' {
+			existing = append(existing, '\n')
+		}
+		content = append(append(existing, "\n# Generated by wordma build\n"...), content...)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(file), err)
+	}
+	return nil
+}
@@ -38,9 +38,18 @@ func runInstall(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	projectConfig, err := loadProjectConfig(projectRoot)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read project config: %v", err))
+		os.Exit(1)
+	}
+
 	utils.PrintInfo("Installing dependencies with pnpm...")
 	
-	err = utils.RunCommandInDir(projectRoot, "pnpm", "install")
+	err = utils.RunCommandWithOptions(utils.CommandOptions{
+		Dir: projectRoot,
+		Env: newWordmaEnv(modeInstall, projectRoot, "", nil, projectConfig).environ(),
+	}, "pnpm", "install")
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to install dependencies: %v", err))
 		os.Exit(1)
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wordma-cli/utils"
+)
+
+// UpdateJournalEntry 记录一次主题更新前的状态，用于回滚
+type UpdateJournalEntry struct {
+	ID           string    `json:"id"`
+	Theme        string    `json:"theme"`
+	Branch       string    `json:"branch"`
+	Commit       string    `json:"commit"`
+	StashRef     string    `json:"stashRef,omitempty"`
+	ConfigBackup string    `json:"configBackup,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// UpdateJournal 保存某个主题的全部更新记录，最新的记录在最后
+type UpdateJournal struct {
+	Entries []UpdateJournalEntry `json:"entries"`
+}
+
+// getJournalDir 返回主题更新日志所在目录
+func getJournalDir(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "journal", themeName)
+}
+
+// getJournalPath 返回主题更新日志文件路径
+func getJournalPath(projectRoot, themeName string) string {
+	return filepath.Join(getJournalDir(projectRoot, themeName), "journal.json")
+}
+
+// loadUpdateJournal 读取主题的更新日志，不存在时返回空日志
+func loadUpdateJournal(projectRoot, themeName string) (*UpdateJournal, error) {
+	journal := &UpdateJournal{}
+
+	data, err := os.ReadFile(getJournalPath(projectRoot, themeName))
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, fmt.Errorf("failed to parse update journal: %v", err)
+	}
+	return journal, nil
+}
+
+// saveUpdateJournal 写入主题的更新日志
+func saveUpdateJournal(projectRoot, themeName string, journal *UpdateJournal) error {
+	if err := utils.CreateDir(getJournalDir(projectRoot, themeName)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getJournalPath(projectRoot, themeName), data, 0644)
+}
+
+// newUpdateJournalEntry 记录更新前的提交并备份配置目录
+func newUpdateJournalEntry(projectRoot, themeName, themePath, branch string) (*UpdateJournalEntry, error) {
+	commit, err := getHeadCommit(themePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current commit: %v", err)
+	}
+
+	journal, err := loadUpdateJournal(projectRoot, themeName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &UpdateJournalEntry{
+		ID:        newJournalID(projectRoot, themeName, journal, now),
+		Theme:     themeName,
+		Branch:    branch,
+		Commit:    commit,
+		CreatedAt: now,
+	}
+
+	configPath := filepath.Join(themePath, "config")
+	if utils.FileExists(configPath) {
+		backupPath := filepath.Join(getJournalDir(projectRoot, themeName), entry.ID, "config")
+		if utils.FileExists(backupPath) {
+			os.RemoveAll(backupPath)
+		}
+		if err := utils.CopyDirectory(configPath, backupPath); err != nil {
+			return nil, fmt.Errorf("failed to backup config directory: %v", err)
+		}
+		entry.ConfigBackup = backupPath
+	}
+
+	return entry, nil
+}
+
+// newJournalID 生成按时间排序的记录号，同一秒内的多次更新加上序号区分
+func newJournalID(projectRoot, themeName string, journal *UpdateJournal, now time.Time) string {
+	taken := func(id string) bool {
+		for _, entry := range journal.Entries {
+			if entry.ID == id {
+				return true
+			}
+		}
+		return utils.FileExists(filepath.Join(getJournalDir(projectRoot, themeName), id))
+	}
+
+	base := now.Format("20060102-150405")
+	id := base
+	for i := 2; taken(id); i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	return id
+}
+
+// appendUpdateJournalEntry 将一条更新记录追加到日志末尾
+func appendUpdateJournalEntry(projectRoot, themeName string, entry *UpdateJournalEntry) error {
+	journal, err := loadUpdateJournal(projectRoot, themeName)
+	if err != nil {
+		return err
+	}
+	journal.Entries = append(journal.Entries, *entry)
+	return saveUpdateJournal(projectRoot, themeName, journal)
+}
+
+// discardUpdateJournalEntry 删除未写入日志的记录留下的配置备份
+func discardUpdateJournalEntry(entry *UpdateJournalEntry) {
+	if entry != nil && entry.ConfigBackup != "" {
+		os.RemoveAll(filepath.Dir(entry.ConfigBackup))
+	}
+}
+
+// getHeadCommit 获取当前 HEAD 的完整提交哈希
+func getHeadCommit(repoPath string) (string, error) {
+	cmd := utils.NewCommand("git", "rev-parse", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// getLatestStashRef 获取最新 stash 的提交哈希（stash@{n} 的序号会变化，哈希不会）
+func getLatestStashRef(repoPath string) (string, error) {
+	cmd := utils.NewCommand("git", "rev-parse", "refs/stash")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// findStashIndex 查找指定哈希在 stash 列表中的位置，不存在时返回 -1
+func findStashIndex(repoPath, stashRef string) (int, error) {
+	cmd := utils.NewCommand("git", "stash", "list", "--format=%H")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return -1, err
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if strings.TrimSpace(line) == stashRef {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initTestRepo 创建带一次提交的 git 仓库
+func initTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeRepoFiles(t, dir, files)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func writeRepoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func readRepoFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNewJournalID(t *testing.T) {
+	projectRoot := t.TempDir()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	journal := &UpdateJournal{}
+	if id := newJournalID(projectRoot, "t", journal, now); id != "20250102-030405" {
+		t.Errorf("unexpected id %s", id)
+	}
+
+	// 同一秒内已有记录，或留有同名的备份目录时加上序号
+	journal.Entries = append(journal.Entries, UpdateJournalEntry{ID: "20250102-030405"})
+	if err := os.MkdirAll(filepath.Join(getJournalDir(projectRoot, "t"), "20250102-030405-2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if id := newJournalID(projectRoot, "t", journal, now); id != "20250102-030405-3" {
+		t.Errorf("unexpected id %s", id)
+	}
+}
+
+func TestUpdateJournalEntriesInSameSecond(t *testing.T) {
+	projectRoot := t.TempDir()
+	themePath := initTestRepo(t, map[string]string{"config/site.yaml": "title: A\n"})
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		entry, err := newUpdateJournalEntry(projectRoot, "t", themePath, "main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := appendUpdateJournalEntry(projectRoot, "t", entry); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	journal, err := loadUpdateJournal(projectRoot, "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journal.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(journal.Entries))
+	}
+	if ids[0] == ids[1] || journal.Entries[0].ConfigBackup == journal.Entries[1].ConfigBackup {
+		t.Errorf("expected distinct ids and backups, got %v", journal.Entries)
+	}
+	for _, entry := range journal.Entries {
+		if readRepoFile(t, entry.ConfigBackup, "site.yaml") != "title: A\n" {
+			t.Errorf("unexpected config backup in %s", entry.ConfigBackup)
+		}
+	}
+}
+
+func TestRollbackUpdate(t *testing.T) {
+	projectRoot := t.TempDir()
+	themePath := initTestRepo(t, map[string]string{
+		"config/site.yaml": "title: A\n",
+		"layout.html":      "<p>v1</p>\n",
+	})
+
+	// 更新前：记录状态，stash 本地更改
+	entry, err := newUpdateJournalEntry(projectRoot, "t", themePath, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeRepoFiles(t, themePath, map[string]string{"layout.html": "<p>local</p>\n"})
+	runGit(t, themePath, "stash", "push", "-q")
+	if entry.StashRef, err = getLatestStashRef(themePath); err != nil {
+		t.Fatal(err)
+	}
+	before := entry.Commit
+
+	// 模拟更新带来的新提交
+	writeRepoFiles(t, themePath, map[string]string{"config/site.yaml": "title: B\n", "layout.html": "<p>v2</p>\n"})
+	runGit(t, themePath, "commit", "-q", "-am", "update")
+
+	if err := rollbackUpdate(themePath, *entry); err != nil {
+		t.Fatal(err)
+	}
+	if head := runGit(t, themePath, "rev-parse", "HEAD"); head != before {
+		t.Errorf("expected HEAD %s, got %s", before, head)
+	}
+	if got := readRepoFile(t, themePath, "layout.html"); got != "<p>local</p>\n" {
+		t.Errorf("expected stashed change to be restored, got %q", got)
+	}
+	if got := readRepoFile(t, themePath, "config/site.yaml"); got != "title: A\n" {
+		t.Errorf("expected config to be restored, got %q", got)
+	}
+	if stashes := runGit(t, themePath, "stash", "list"); stashes != "" {
+		t.Errorf("expected the stash to be dropped, got %q", stashes)
+	}
+}
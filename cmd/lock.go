@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+// lockWait 为 true 时等待其他 wordma 命令释放项目锁，而不是直接退出
+var lockWait bool
+
+// heldLocks 是当前进程持有的锁，exit 退出前释放
+var (
+	heldLocks   []*utils.FileLock
+	heldLocksMu sync.Mutex
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&lockWait, "wait", false, "Wait for other wordma commands in this project to finish instead of failing")
+}
+
+// getLockPath 返回项目锁文件的路径
+func getLockPath(projectRoot string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "lock")
+}
+
+// lockProject 获取项目锁，失败时打印持有者信息并退出。
+// 修改主题或 .deploy 的命令在开始修改前调用；持有锁之后应通过 exit 退出
+func lockProject(projectRoot string, cmd *cobra.Command) *utils.FileLock {
+	lock, err := utils.AcquireLock(getLockPath(projectRoot), cmd.CommandPath(), lockWait, func(locked *utils.LockedError) {
+		utils.PrintInfo(fmt.Sprintf("Waiting, %v", locked))
+	})
+	if err != nil {
+		if locked, ok := err.(*utils.LockedError); ok {
+			utils.PrintError(fmt.Sprintf("Cannot continue, %v", locked))
+			utils.PrintInfo("Use --wait to wait for it to finish")
+		} else {
+			utils.PrintError(fmt.Sprintf("Failed to lock project: %v", err))
+		}
+		os.Exit(1)
+	}
+
+	if lock.Stale != nil {
+		utils.PrintWarning(fmt.Sprintf("Previous '%s' (PID %d) did not finish cleanly, its lock was released",
+			lock.Stale.Command, lock.Stale.PID))
+	}
+	holdLock(lock)
+	return lock
+}
+
+// holdLock 记录当前进程持有的锁，供 exit 释放
+func holdLock(lock *utils.FileLock) {
+	heldLocksMu.Lock()
+	defer heldLocksMu.Unlock()
+	heldLocks = append(heldLocks, lock)
+}
+
+// exit 释放当前进程持有的锁后退出。os.Exit 不会执行 defer，
+// 直接退出会在锁文件中留下进程信息，下次运行时被误报为上次没有正常结束
+func exit(code int) {
+	heldLocksMu.Lock()
+	for _, lock := range heldLocks {
+		lock.Release()
+	}
+	os.Exit(code)
+}
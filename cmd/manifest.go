@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// ThemeManifest 是主题 package.json 中 "wordma" 字段声明的主题信息
+type ThemeManifest struct {
+	Name         string `json:"-"`
+	DisplayName  string `json:"displayName"`
+	Version      string `json:"version"`
+	Core         string `json:"core"`
+	Node         string `json:"node"`
+	OutDir       string `json:"outDir"`
+	ConfigSchema string `json:"configSchema"`
+	SearchIndex  string `json:"searchIndex"`
+}
+
+// Title 返回用于展示的主题名称
+func (m *ThemeManifest) Title() string {
+	if m.DisplayName != "" {
+		return m.DisplayName
+	}
+	return m.Name
+}
+
+// coreDependencyNames 是项目 package.json 中可能声明 wordma core 的依赖名
+var coreDependencyNames = []string{"@wordma/core", "wordma-core"}
+
+// packageJSON 是读取 package.json 时关心的字段
+type packageJSON struct {
+	Name            string            `json:"name"`
+	Version         string            `json:"version"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Engines         map[string]string `json:"engines"`
+	Wordma          *ThemeManifest    `json:"wordma"`
+}
+
+// parseThemeManifest 从 package.json 内容解析主题清单；
+// 未声明的版本和 Node 要求回退到 package.json 的 version 和 engines.node
+func parseThemeManifest(data []byte) (*ThemeManifest, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %v", err)
+	}
+
+	manifest := pkg.Wordma
+	if manifest == nil {
+		manifest = &ThemeManifest{}
+	}
+	manifest.Name = pkg.Name
+	if manifest.Version == "" {
+		manifest.Version = pkg.Version
+	}
+	if manifest.Node == "" {
+		manifest.Node = pkg.Engines["node"]
+	}
+	return manifest, nil
+}
+
+// loadThemeManifest 读取主题目录中 package.json 的主题清单
+func loadThemeManifest(themePath string) (*ThemeManifest, error) {
+	data, err := os.ReadFile(filepath.Join(themePath, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseThemeManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Name == "" {
+		manifest.Name = filepath.Base(themePath)
+	}
+	return manifest, nil
+}
+
+// getProjectCoreVersion 获取项目使用的 wordma core 版本，无法确定时返回空字符串
+func getProjectCoreVersion(projectRoot string) string {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg packageJSON
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+
+	for _, name := range coreDependencyNames {
+		spec := pkg.Dependencies[name]
+		if spec == "" {
+			spec = pkg.DevDependencies[name]
+		}
+		if spec == "" {
+			continue
+		}
+
+		// 优先使用实际安装的版本
+		installed, err := os.ReadFile(filepath.Join(projectRoot, "node_modules", filepath.FromSlash(name), "package.json"))
+		if err == nil {
+			var dep packageJSON
+			if json.Unmarshal(installed, &dep) == nil && dep.Version != "" {
+				return dep.Version
+			}
+		}
+
+		// 否则使用声明的版本下限
+		spec = strings.TrimLeft(strings.Fields(spec + " ")[0], "^~>=v")
+		if _, err := utils.ParseVersion(spec); err == nil {
+			return spec
+		}
+	}
+
+	// wordma 模板项目本身就是 core
+	if pkg.Name == "wordma" {
+		return pkg.Version
+	}
+	return ""
+}
+
+// compatProblem 是一条主题兼容性问题
+type compatProblem struct {
+	Message string
+	Fatal   bool
+}
+
+// checkThemeCompatibility 检查主题声明的 core 版本范围和 Node 版本要求
+func checkThemeCompatibility(projectRoot string, manifest *ThemeManifest) []compatProblem {
+	var problems []compatProblem
+
+	if manifest.Core != "" {
+		coreVersion := getProjectCoreVersion(projectRoot)
+		if coreVersion == "" {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Cannot determine the project's wordma core version, theme requires core %s", manifest.Core),
+			})
+		} else if ok, err := utils.SatisfiesVersionRange(coreVersion, manifest.Core); err != nil {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Invalid core version range '%s' in theme manifest: %v", manifest.Core, err),
+			})
+		} else if !ok {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Theme requires wordma core %s, but the project uses %s", manifest.Core, coreVersion),
+				Fatal:   true,
+			})
+		}
+	}
+
+	if manifest.Node != "" {
+		if !utils.CheckCommand("node") {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Theme requires Node.js %s, but Node.js is not installed", manifest.Node),
+				Fatal:   true,
+			})
+		} else if nodeVersion, err := utils.GetCommandVersion("node", "--version"); err != nil {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Failed to check Node.js version, theme requires %s", manifest.Node),
+			})
+		} else if ok, err := utils.SatisfiesVersionRange(nodeVersion, manifest.Node); err != nil {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Invalid Node.js version range '%s' in theme manifest: %v", manifest.Node, err),
+			})
+		} else if !ok {
+			problems = append(problems, compatProblem{
+				Message: fmt.Sprintf("Theme requires Node.js %s, but %s is installed", manifest.Node, nodeVersion),
+				Fatal:   true,
+			})
+		}
+	}
+
+	return problems
+}
+
+// enforceThemeCompatibility 打印兼容性问题，存在严重问题且未跳过检查时返回 false
+func enforceThemeCompatibility(projectRoot string, manifest *ThemeManifest, skip bool) bool {
+	compatible := true
+	for _, problem := range checkThemeCompatibility(projectRoot, manifest) {
+		if problem.Fatal && !skip {
+			utils.PrintError(problem.Message)
+			compatible = false
+		} else {
+			utils.PrintWarning(problem.Message)
+		}
+	}
+
+	if !compatible {
+		utils.PrintInfo("Use --skip-compat to ignore compatibility checks")
+	}
+	return compatible
+}
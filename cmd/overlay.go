@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"wordma-cli/utils"
+)
+
+// overlayFile 记录覆盖层写入的一个主题文件
+type overlayFile struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+}
+
+// overlayState 记录覆盖层对主题目录做的全部修改，用于事后恢复
+type overlayState struct {
+	Files       []overlayFile `json:"files"`
+	CreatedDirs []string      `json:"createdDirs"`
+}
+
+// getOverridesDir 返回项目中主题覆盖层的目录
+func getOverridesDir(projectRoot, themeName string) string {
+	return filepath.Join(projectRoot, "overrides", themeName)
+}
+
+// getOverlayStateDir 返回覆盖层状态和原文件备份的目录
+func getOverlayStateDir(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "overlay", themeName)
+}
+
+// getOverlayLockPath 返回主题覆盖层锁文件的路径
+func getOverlayLockPath(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "overlay", themeName+".lock")
+}
+
+// lockThemeOverlay 获取主题的覆盖层锁。dev、build 和 update theme 在应用或恢复覆盖层之前调用，
+// 避免一个命令在另一个命令运行期间恢复或改写主题文件；dev 在开发服务器运行期间一直持有
+func lockThemeOverlay(projectRoot, themeName, command string, out io.Writer) (*utils.FileLock, error) {
+	holder := func(locked *utils.LockedError) string {
+		if locked.Info == nil || locked.Info.PID == 0 {
+			return "another wordma command"
+		}
+		return fmt.Sprintf("'%s' (PID %d)", locked.Info.Command, locked.Info.PID)
+	}
+
+	lock, err := utils.AcquireLock(getOverlayLockPath(projectRoot, themeName), command, lockWait, func(locked *utils.LockedError) {
+		utils.FprintInfo(out, fmt.Sprintf("Waiting, overrides for theme '%s' are applied by %s", themeName, holder(locked)))
+	})
+	if locked, ok := err.(*utils.LockedError); ok {
+		return nil, fmt.Errorf("overrides for theme '%s' are applied by %s, stop it first or use --wait", themeName, holder(locked))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock overrides for theme '%s': %v", themeName, err)
+	}
+	holdLock(lock)
+	return lock, nil
+}
+
+// isOverlayConfigFile 判断覆盖层中的文件是否需要深度合并
+func isOverlayConfigFile(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	return strings.HasPrefix(relPath, "config/") && utils.IsConfigFile(relPath)
+}
+
+// listOverrideFiles 列出覆盖层中的全部文件（相对路径）
+func listOverrideFiles(overridesDir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(overridesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(overridesDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, relPath)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// withThemeOverlay 在执行 fn 期间将 overrides/<theme>/ 覆盖到主题目录，结束后恢复主题原样；
+// 调用方需要先通过 lockThemeOverlay 持有覆盖层锁
+func withThemeOverlay(projectRoot, themeName, themePath string, out io.Writer, fn func() error) error {
+	// 上次运行异常退出时可能留下未恢复的覆盖层
+	if err := restoreThemeOverlay(projectRoot, themeName, themePath); err != nil {
+		return fmt.Errorf("failed to restore previous overlay: %v", err)
+	}
+
+	overridesDir := getOverridesDir(projectRoot, themeName)
+	if !utils.FileExists(overridesDir) {
+		return fn()
+	}
+
+	// 运行期间忽略中断信号，让子进程先退出，再恢复主题文件
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	count, err := applyThemeOverlay(projectRoot, themeName, themePath)
+	if err != nil {
+		restoreThemeOverlay(projectRoot, themeName, themePath)
+		return fmt.Errorf("failed to apply overrides: %v", err)
+	}
+	utils.FprintInfo(out, fmt.Sprintf("Applied %d override file(s) from overrides/%s", count, themeName))
+
+	runErr := fn()
+
+	if err := restoreThemeOverlay(projectRoot, themeName, themePath); err != nil {
+		utils.FprintWarning(out, fmt.Sprintf("Failed to restore theme files after overlay: %v", err))
+	}
+	return runErr
+}
+
+// applyThemeOverlay 将覆盖层写入主题目录：配置文件深度合并，其余文件直接替换
+func applyThemeOverlay(projectRoot, themeName, themePath string) (int, error) {
+	overridesDir := getOverridesDir(projectRoot, themeName)
+	stateDir := getOverlayStateDir(projectRoot, themeName)
+	backupDir := filepath.Join(stateDir, "backup")
+
+	files, err := listOverrideFiles(overridesDir)
+	if err != nil {
+		return 0, err
+	}
+
+	state := &overlayState{}
+	for _, relPath := range files {
+		srcPath := filepath.Join(overridesDir, relPath)
+		dstPath := filepath.Join(themePath, relPath)
+		existed := utils.FileExists(dstPath)
+
+		// 记录新建的目录，恢复时删除
+		for dir := filepath.Dir(dstPath); dir != themePath && !utils.FileExists(dir); dir = filepath.Dir(dir) {
+			state.CreatedDirs = append(state.CreatedDirs, dir)
+		}
+		if err := utils.CreateDir(filepath.Dir(dstPath)); err != nil {
+			return 0, err
+		}
+
+		// 先备份原文件并保存状态，再修改主题文件
+		if existed {
+			backupPath := filepath.Join(backupDir, relPath)
+			if err := utils.CreateDir(filepath.Dir(backupPath)); err != nil {
+				return 0, err
+			}
+			if err := utils.CopyFile(dstPath, backupPath); err != nil {
+				return 0, err
+			}
+		}
+		state.Files = append(state.Files, overlayFile{Path: relPath, Existed: existed})
+		if err := saveOverlayState(stateDir, state); err != nil {
+			return 0, err
+		}
+
+		if existed && isOverlayConfigFile(relPath) {
+			err = mergeOverlayConfigFile(dstPath, srcPath)
+		} else {
+			err = utils.CopyFile(srcPath, dstPath)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", filepath.ToSlash(relPath), err)
+		}
+	}
+
+	return len(files), nil
+}
+
+// mergeOverlayConfigFile 将覆盖层配置深度合并进主题配置文件
+func mergeOverlayConfigFile(themeFile, overrideFile string) error {
+	base, err := os.ReadFile(themeFile)
+	if err != nil {
+		return err
+	}
+	override, err := os.ReadFile(overrideFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := utils.MergeConfigData(themeFile, base, override)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(themeFile, data, 0644)
+}
+
+// saveOverlayState 保存覆盖层状态
+func saveOverlayState(stateDir string, state *overlayState) error {
+	if err := utils.CreateDir(stateDir); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir, "state.json"), data, 0644)
+}
+
+// restoreThemeOverlay 根据状态文件恢复被覆盖层修改过的主题文件
+func restoreThemeOverlay(projectRoot, themeName, themePath string) error {
+	stateDir := getOverlayStateDir(projectRoot, themeName)
+	data, err := os.ReadFile(filepath.Join(stateDir, "state.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state overlayState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse overlay state: %v", err)
+	}
+
+	backupDir := filepath.Join(stateDir, "backup")
+	for _, file := range state.Files {
+		dstPath := filepath.Join(themePath, file.Path)
+		if file.Existed {
+			if err := utils.CopyFile(filepath.Join(backupDir, file.Path), dstPath); err != nil {
+				return err
+			}
+		} else if err := os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	// 由深到浅删除覆盖层创建的空目录
+	sort.Slice(state.CreatedDirs, func(i, j int) bool {
+		return len(state.CreatedDirs[i]) > len(state.CreatedDirs[j])
+	})
+	for _, dir := range state.CreatedDirs {
+		os.Remove(dir)
+	}
+
+	return os.RemoveAll(stateDir)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"wordma-cli/utils"
+)
+
+// defaultPrecompressMinSize 是默认的最小压缩大小
+const defaultPrecompressMinSize = 1024
+
+// defaultPrecompressExtensions 是默认预压缩的文件类型
+var defaultPrecompressExtensions = []string{
+	".html", ".htm", ".css", ".js", ".mjs", ".json", ".xml", ".svg",
+	".txt", ".map", ".ico", ".wasm", ".webmanifest", ".ttf", ".otf", ".eot",
+}
+
+// resolvePrecompress 根据项目配置和 --precompress 确定预压缩设置，不预压缩时返回 nil
+func resolvePrecompress(config *PrecompressConfig, flag, flagSet bool) *utils.PrecompressOptions {
+	if config == nil {
+		config = &PrecompressConfig{}
+		if !flagSet {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+
+	opts := &utils.PrecompressOptions{
+		MinSize:    defaultPrecompressMinSize,
+		Extensions: defaultPrecompressExtensions,
+		Brotli:     utils.CheckCommand("brotli"),
+	}
+	if config.MinSize != nil {
+		opts.MinSize = int64(*config.MinSize)
+	}
+	if len(config.Extensions) > 0 {
+		opts.Extensions = config.Extensions
+	}
+	if config.Brotli != nil {
+		opts.Brotli = *config.Brotli
+	}
+	return opts
+}
+
+// precompress 在新版本中生成压缩文件，上一个上线版本中内容相同的文件的压缩结果直接复用
+func (b *themeBuild) precompress(releaseDir, previousRelease string) error {
+	opts := *b.Precompress
+	if opts.Brotli && !utils.CheckCommand("brotli") {
+		utils.FprintWarning(b.Out, "brotli is not installed, only .gz files are generated")
+		opts.Brotli = false
+	}
+	if previousRelease != "" {
+		opts.Previous = filepath.Join(getReleasesDir(b.ProjectRoot, b.Name), previousRelease)
+	}
+
+	result, err := utils.Precompress(releaseDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to precompress build output: %v", err)
+	}
+
+	formats := "gzip"
+	if opts.Brotli {
+		formats = "gzip, brotli"
+	}
+	utils.FprintInfo(b.Out, fmt.Sprintf("Precompressed %d file(s) (%s), %d up to date, saving %s",
+		result.Written, formats, result.UpToDate, utils.FormatSize(result.Saved)))
+	return nil
+}
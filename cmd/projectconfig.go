@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"wordma-cli/utils"
+)
+
+// projectConfigFile 是项目根目录下的 wordma CLI 配置文件
+const projectConfigFile = "wordma.config.json"
+
+// ProjectConfig 是 wordma.config.json 的内容
+type ProjectConfig struct {
+	// BaseURL 站点地址，作为 WORDMA_BASE_URL 传给主题
+	BaseURL string `json:"baseUrl"`
+	// Budgets 适用于全部主题的体积预算
+	Budgets *BudgetConfig `json:"budgets"`
+	// Precompress 适用于全部主题的预压缩设置，设置后构建时生成 .gz/.br 文件
+	Precompress *PrecompressConfig `json:"precompress"`
+	// HashAssets 适用于全部主题的资源文件名哈希化设置
+	HashAssets *HashAssetsConfig `json:"hashAssets"`
+	// Sitemap 适用于全部主题的 sitemap.xml 生成设置
+	Sitemap *SitemapConfig `json:"sitemap"`
+	// SEO 适用于全部主题的 wordma check seo 设置
+	SEO *SEOConfig `json:"seo"`
+	// Search 适用于全部主题的搜索索引生成设置
+	Search *SearchConfig `json:"search"`
+	// Feeds 适用于全部主题的 RSS/Atom/JSON Feed 生成设置
+	Feeds *FeedConfig `json:"feeds"`
+	// SRI 适用于全部主题的 integrity 属性和 CSP 建议生成设置
+	SRI *SRIConfig `json:"sri"`
+	// Hosting 适用于全部主题的重定向和响应头，构建时按部署目标生成托管配置
+	Hosting *HostingConfig              `json:"hosting"`
+	Hooks   map[string]HookCommands     `json:"hooks"`
+	Themes  map[string]ThemeBuildConfig `json:"themes"`
+}
+
+// ThemeBuildConfig 是项目配置中针对单个主题的构建设置
+type ThemeBuildConfig struct {
+	// OutDir 主题构建输出目录，相对于项目根目录
+	OutDir string `json:"outDir"`
+	// BaseURL 主题的站点地址，设置后代替项目的 baseUrl
+	BaseURL string `json:"baseUrl"`
+	// Budgets 主题自己的体积预算，设置后代替项目的预算
+	Budgets *BudgetConfig `json:"budgets"`
+	// Precompress 主题自己的预压缩设置，设置后代替项目的设置
+	Precompress *PrecompressConfig `json:"precompress"`
+	// HashAssets 主题自己的资源文件名哈希化设置，设置后代替项目的设置
+	HashAssets *HashAssetsConfig `json:"hashAssets"`
+	// Sitemap 主题自己的 sitemap.xml 生成设置，设置后代替项目的设置
+	Sitemap *SitemapConfig `json:"sitemap"`
+	// SEO 主题自己的 wordma check seo 设置，设置后代替项目的设置
+	SEO *SEOConfig `json:"seo"`
+	// Search 主题自己的搜索索引生成设置，设置后代替项目的设置
+	Search *SearchConfig `json:"search"`
+	// Feeds 主题自己的订阅生成设置，设置后代替项目的设置
+	Feeds *FeedConfig `json:"feeds"`
+	// SRI 主题自己的 integrity 属性和 CSP 建议生成设置，设置后代替项目的设置
+	SRI *SRIConfig `json:"sri"`
+	// Hosting 主题自己的托管配置，设置后代替项目的设置
+	Hosting *HostingConfig `json:"hosting"`
+}
+
+// BudgetConfig 是构建输出的体积预算，未设置的项不检查
+type BudgetConfig struct {
+	Total     SizeLimit    `json:"total"`
+	TotalGzip SizeLimit    `json:"totalGzip"`
+	MaxImage  SizeLimit    `json:"maxImage"`
+	Files     []FileBudget `json:"files"`
+}
+
+// FileBudget 是匹配 glob 的每个文件的体积上限
+type FileBudget struct {
+	Glob string    `json:"glob"`
+	Size SizeLimit `json:"size"`
+	Gzip SizeLimit `json:"gzip"`
+}
+
+// PrecompressConfig 是构建输出的预压缩设置，未设置的项使用默认值
+type PrecompressConfig struct {
+	// Enabled 为 false 时不预压缩，默认启用
+	Enabled *bool `json:"enabled"`
+	// MinSize 小于该大小的文件不压缩，默认 1KB
+	MinSize *SizeLimit `json:"minSize"`
+	// Brotli 是否生成 .br 文件，默认在安装了 brotli 命令时生成
+	Brotli *bool `json:"brotli"`
+	// Extensions 需要压缩的文件扩展名，默认为常见的文本类型
+	Extensions []string `json:"extensions"`
+}
+
+// HashAssetsConfig 是资源文件名哈希化的设置，未设置的项使用默认值
+type HashAssetsConfig struct {
+	// Enabled 为 false 时不哈希化，默认启用
+	Enabled *bool `json:"enabled"`
+	// Extensions 需要哈希化的文件扩展名，默认为 CSS、JS、图片和字体
+	Extensions []string `json:"extensions"`
+	// Exclude 保留原名的文件（glob），追加在默认的 sw.js 等之后
+	Exclude []string `json:"exclude"`
+	// Manifest 映射文件的路径，相对于输出目录，默认 manifest.json
+	Manifest string `json:"manifest"`
+}
+
+// SitemapConfig 是 sitemap.xml 和 robots.txt 的生成设置
+type SitemapConfig struct {
+	// Enabled 为 false 时不生成，默认启用
+	Enabled *bool `json:"enabled"`
+	// Exclude 不写入 sitemap 的页面（glob），追加在默认的 404.html 之后
+	Exclude []string `json:"exclude"`
+	// Robots 为 false 时不生成 robots.txt
+	Robots *bool `json:"robots"`
+}
+
+// SEOConfig 是 wordma check seo 的设置
+type SEOConfig struct {
+	// Lang 页面应使用的语言，设置后检查每个页面的 lang 是否与之一致
+	Lang string `json:"lang"`
+	// FailOn 出现该级别及以上的问题时命令失败：error、warning、info 或 none，默认 error
+	FailOn string `json:"failOn"`
+	// Rules 调整规则的级别：error、warning、info 或 off
+	Rules map[string]string `json:"rules"`
+	// Exclude 不检查的页面（glob）
+	Exclude []string `json:"exclude"`
+}
+
+// SearchConfig 是搜索索引的生成设置
+type SearchConfig struct {
+	// Enabled 为 false 时不生成，默认启用
+	Enabled *bool `json:"enabled"`
+	// Output 索引文件的路径，相对于输出目录，代替主题清单的 wordma.searchIndex
+	Output string `json:"output"`
+	// Exclude 不加入索引的页面（glob），追加在默认的 404.html 之后
+	Exclude []string `json:"exclude"`
+	// MaxText 每个页面保留的正文字符数，默认 5000，0 表示使用默认值
+	MaxText int `json:"maxText"`
+}
+
+// FeedConfig 是 feed.xml、atom.xml 和 feed.json 的生成设置
+type FeedConfig struct {
+	// Enabled 为 false 时不生成，默认启用
+	Enabled *bool `json:"enabled"`
+	// Include 作为文章的页面（glob），默认为全部带发布时间的页面
+	Include []string `json:"include"`
+	// Exclude 不作为文章的页面（glob）
+	Exclude []string `json:"exclude"`
+	// Limit 订阅中最多包含的文章数，默认 20
+	Limit int `json:"limit"`
+	// Formats 生成的格式：rss、atom、json，默认全部
+	Formats []string `json:"formats"`
+	// Title 订阅标题，默认使用首页的标题
+	Title string `json:"title"`
+	// Description 订阅描述，默认使用首页的描述
+	Description string `json:"description"`
+	// Author 作者名称
+	Author string `json:"author"`
+}
+
+// SRIConfig 是 integrity 属性和 CSP 建议的生成设置
+type SRIConfig struct {
+	// Enabled 为 false 时不生成，默认启用
+	Enabled *bool `json:"enabled"`
+	// Algorithm 哈希算法：sha256、sha384 或 sha512，默认 sha384
+	Algorithm string `json:"algorithm"`
+	// CDN 为 false 时不下载固定版本的 CDN 资源计算 integrity
+	CDN *bool `json:"cdn"`
+	// CSP 为 false 时不生成 Content-Security-Policy 建议
+	CSP *bool `json:"csp"`
+	// Exclude 不处理的页面（glob）
+	Exclude []string `json:"exclude"`
+}
+
+// HostingConfig 是重定向和响应头的声明，构建时按部署目标写入输出目录
+type HostingConfig struct {
+	// Target 部署目标：netlify、cloudflare、nginx 或 github-pages
+	Target string `json:"target"`
+	// Redirects 重定向规则
+	Redirects []RedirectRule `json:"redirects"`
+	// Headers 响应头规则
+	Headers []HeaderRule `json:"headers"`
+	// CSP 为 true 时把 integrity 步骤生成的 CSP 建议加入全部页面的响应头
+	CSP bool `json:"csp"`
+}
+
+// RedirectRule 是一条重定向，路径相对于站点根目录，构建时加上 --base-path
+type RedirectRule struct {
+	// From 原路径，结尾的 * 匹配任意后缀
+	From string `json:"from"`
+	// To 目标路径或绝对地址，:splat 替换为 * 匹配到的部分
+	To string `json:"to"`
+	// Status 状态码：301、302、303、307 或 308，默认 301
+	Status int `json:"status"`
+}
+
+// HeaderRule 是为匹配路径的请求添加的响应头
+type HeaderRule struct {
+	// Path 路径，* 匹配任意字符
+	Path string `json:"path"`
+	// Values 响应头名称到值的映射
+	Values map[string]string `json:"values"`
+}
+
+// SizeLimit 是以字节为单位的大小，配置中可以写数字或 "200KB" 这样的字符串
+type SizeLimit int64
+
+// UnmarshalJSON 解析数字或带单位的字符串
+func (l *SizeLimit) UnmarshalJSON(data []byte) error {
+	var number int64
+	if err := json.Unmarshal(data, &number); err == nil {
+		*l = SizeLimit(number)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("size must be a number of bytes or a string like \"200KB\"")
+	}
+	size, err := utils.ParseSize(text)
+	if err != nil {
+		return err
+	}
+	*l = SizeLimit(size)
+	return nil
+}
+
+// loadProjectConfig 读取项目配置，文件不存在时返回空配置
+func loadProjectConfig(projectRoot string) (*ProjectConfig, error) {
+	config := &ProjectConfig{}
+
+	data, err := os.ReadFile(filepath.Join(projectRoot, projectConfigFile))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", projectConfigFile, err)
+	}
+	if err := validateHooks(config.Hooks); err != nil {
+		return nil, fmt.Errorf("%s: %v", projectConfigFile, err)
+	}
+	return config, nil
+}
+
+// theme 返回指定主题的构建设置
+func (c *ProjectConfig) theme(themeName string) ThemeBuildConfig {
+	return c.Themes[themeName]
+}
+
+// budgets 返回适用于指定主题的体积预算，没有预算时返回 nil
+func (c *ProjectConfig) budgets(themeName string) *BudgetConfig {
+	if budgets := c.theme(themeName).Budgets; budgets != nil {
+		return budgets
+	}
+	return c.Budgets
+}
+
+// precompress 返回适用于指定主题的预压缩设置，没有设置时返回 nil
+func (c *ProjectConfig) precompress(themeName string) *PrecompressConfig {
+	if precompress := c.theme(themeName).Precompress; precompress != nil {
+		return precompress
+	}
+	return c.Precompress
+}
+
+// hashAssets 返回适用于指定主题的资源文件名哈希化设置，没有设置时返回 nil
+func (c *ProjectConfig) hashAssets(themeName string) *HashAssetsConfig {
+	if hashAssets := c.theme(themeName).HashAssets; hashAssets != nil {
+		return hashAssets
+	}
+	return c.HashAssets
+}
+
+// sitemap 返回适用于指定主题的 sitemap 设置，没有设置时返回 nil
+func (c *ProjectConfig) sitemap(themeName string) *SitemapConfig {
+	if sitemap := c.theme(themeName).Sitemap; sitemap != nil {
+		return sitemap
+	}
+	return c.Sitemap
+}
+
+// seo 返回适用于指定主题的 SEO 检查设置，没有设置时返回空设置
+func (c *ProjectConfig) seo(themeName string) *SEOConfig {
+	if seo := c.theme(themeName).SEO; seo != nil {
+		return seo
+	}
+	if c.SEO != nil {
+		return c.SEO
+	}
+	return &SEOConfig{}
+}
+
+// search 返回适用于指定主题的搜索索引设置，没有设置时返回 nil
+func (c *ProjectConfig) search(themeName string) *SearchConfig {
+	if search := c.theme(themeName).Search; search != nil {
+		return search
+	}
+	return c.Search
+}
+
+// feeds 返回适用于指定主题的订阅设置，没有设置时返回 nil
+func (c *ProjectConfig) feeds(themeName string) *FeedConfig {
+	if feeds := c.theme(themeName).Feeds; feeds != nil {
+		return feeds
+	}
+	return c.Feeds
+}
+
+// sri 返回适用于指定主题的 integrity 设置，没有设置时返回 nil
+func (c *ProjectConfig) sri(themeName string) *SRIConfig {
+	if sri := c.theme(themeName).SRI; sri != nil {
+		return sri
+	}
+	return c.SRI
+}
+
+// hosting 返回适用于指定主题的托管配置，没有设置时返回 nil
+func (c *ProjectConfig) hosting(themeName string) *HostingConfig {
+	if hosting := c.theme(themeName).Hosting; hosting != nil {
+		return hosting
+	}
+	return c.Hosting
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var buildRollbackCmd = &cobra.Command{
+	Use:   "rollback <theme> [release-id]",
+	Short: "Switch a theme back to a previous build",
+	Long: `Switch .deploy/<theme> back to a kept release without rebuilding.
+Without a release id the release built before the current one is used.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runBuildRollback,
+}
+
+var buildReleasesCmd = &cobra.Command{
+	Use:   "releases <theme>",
+	Short: "List the kept builds of a theme",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBuildReleases,
+}
+
+func init() {
+	buildCmd.AddCommand(buildRollbackCmd)
+	buildCmd.AddCommand(buildReleasesCmd)
+}
+
+func runBuildRollback(cmd *cobra.Command, args []string) {
+	themeName := args[0]
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		exit(1)
+	}
+
+	lock := lockProject(projectRoot, cmd)
+	defer lock.Release()
+
+	index, err := loadReleaseIndex(projectRoot, themeName)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read releases: %v", err))
+		exit(1)
+	}
+	if len(index.Releases) == 0 {
+		utils.PrintError(fmt.Sprintf("Theme '%s' has no kept releases", themeName))
+		exit(1)
+	}
+
+	var target string
+	if len(args) > 1 {
+		target = args[1]
+	} else {
+		current := index.find(index.Current)
+		if current <= 0 {
+			utils.PrintError(fmt.Sprintf("Theme '%s' has no release older than the current one", themeName))
+			exit(1)
+		}
+		target = index.Releases[current-1].ID
+	}
+	if target == index.Current {
+		utils.PrintWarning(fmt.Sprintf("Release '%s' is already live", target))
+		return
+	}
+
+	if err := activateRelease(projectRoot, themeName, index, target); err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+	utils.PrintSuccess(fmt.Sprintf("Theme '%s' rolled back to release '%s'", themeName, target))
+}
+
+func runBuildReleases(cmd *cobra.Command, args []string) {
+	themeName := args[0]
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		exit(1)
+	}
+
+	index, err := loadReleaseIndex(projectRoot, themeName)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read releases: %v", err))
+		exit(1)
+	}
+	if len(index.Releases) == 0 {
+		utils.PrintInfo(fmt.Sprintf("Theme '%s' has no kept releases", themeName))
+		return
+	}
+
+	for i := len(index.Releases) - 1; i >= 0; i-- {
+		release := index.Releases[i]
+		marker := " "
+		if release.ID == index.Current {
+			marker = utils.ColorText("*", "green")
+		}
+		line := fmt.Sprintf("%s %s  %s", marker, release.ID, release.CreatedAt.Format("2006-01-02 15:04:05"))
+		if release.ThemeVersion != "" {
+			line += "  v" + release.ThemeVersion
+		}
+		fmt.Println(line)
+	}
+}
+
+// releaseInfo 是一次保留的构建结果
+type releaseInfo struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ThemeVersion string    `json:"themeVersion,omitempty"`
+	Fingerprint  string    `json:"fingerprint,omitempty"`
+	BasePath     string    `json:"basePath,omitempty"`
+	OutDir       string    `json:"outDir,omitempty"` // 构建输出所在的目录（相对项目根目录）
+}
+
+// releaseIndex 记录主题的全部保留版本和当前上线的版本
+type releaseIndex struct {
+	Current  string        `json:"current"`
+	Releases []releaseInfo `json:"releases"`
+}
+
+// getReleasesDir 返回主题构建版本的保存目录
+func getReleasesDir(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "releases", themeName)
+}
+
+// getLiveDir 返回主题当前上线的目录
+func getLiveDir(projectRoot, themeName string) string {
+	return filepath.Join(projectRoot, ".deploy", themeName)
+}
+
+// loadReleaseIndex 读取主题的版本索引，不存在时返回空索引
+func loadReleaseIndex(projectRoot, themeName string) (*releaseIndex, error) {
+	index := &releaseIndex{}
+	data, err := os.ReadFile(filepath.Join(getReleasesDir(projectRoot, themeName), "releases.json"))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse release index: %v", err)
+	}
+	return index, nil
+}
+
+// saveReleaseIndex 保存主题的版本索引
+func saveReleaseIndex(projectRoot, themeName string, index *releaseIndex) error {
+	dir := getReleasesDir(projectRoot, themeName)
+	if err := utils.CreateDir(dir); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "releases.json"), data, 0644)
+}
+
+// find 返回指定版本在索引中的位置，不存在时返回 -1
+func (idx *releaseIndex) find(id string) int {
+	for i, release := range idx.Releases {
+		if release.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// newReleaseID 生成按时间排序的版本号
+func newReleaseID(index *releaseIndex, now time.Time) string {
+	base := now.Format("20060102-150405")
+	id := base
+	for i := 2; index.find(id) >= 0; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	return id
+}
+
+// createRelease 将构建输出移动为主题的一个新版本并返回版本信息
+func createRelease(projectRoot, themeName, outDir string, manifest *ThemeManifest, fingerprint, basePath string) (*releaseIndex, releaseInfo, error) {
+	index, err := loadReleaseIndex(projectRoot, themeName)
+	if err != nil {
+		return nil, releaseInfo{}, err
+	}
+
+	now := time.Now()
+	release := releaseInfo{ID: newReleaseID(index, now), CreatedAt: now, Fingerprint: fingerprint, BasePath: basePath}
+	if manifest != nil {
+		release.ThemeVersion = manifest.Version
+	}
+	if rel, err := filepath.Rel(projectRoot, outDir); err == nil {
+		release.OutDir = filepath.ToSlash(rel)
+	}
+
+	releaseDir := filepath.Join(getReleasesDir(projectRoot, themeName), release.ID)
+	if err := utils.CreateDir(filepath.Dir(releaseDir)); err != nil {
+		return nil, releaseInfo{}, err
+	}
+	if err := utils.MoveDirectory(outDir, releaseDir); err != nil {
+		return nil, releaseInfo{}, fmt.Errorf("failed to store release: %v", err)
+	}
+
+	index.Releases = append(index.Releases, release)
+	if err := saveReleaseIndex(projectRoot, themeName, index); err != nil {
+		return nil, releaseInfo{}, err
+	}
+	return index, release, nil
+}
+
+// discardRelease 删除没有上线的版本
+func discardRelease(projectRoot, themeName string, index *releaseIndex, id string) error {
+	if i := index.find(id); i >= 0 {
+		index.Releases = append(index.Releases[:i], index.Releases[i+1:]...)
+	}
+	if err := removeRelease(projectRoot, themeName, id); err != nil {
+		return err
+	}
+	return saveReleaseIndex(projectRoot, themeName, index)
+}
+
+// removeRelease 删除版本目录和与版本一起保存的文件
+func removeRelease(projectRoot, themeName, id string) error {
+	if err := os.RemoveAll(filepath.Join(getReleasesDir(projectRoot, themeName), id)); err != nil {
+		return err
+	}
+	if err := os.Remove(getReleaseNginxConfigPath(projectRoot, themeName, id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// activateRelease 将指定版本切换为 .deploy/<theme>。
+// 版本先复制到 .deploy 下的临时目录，再与上线目录交换，切换过程中始终有完整的站点
+func activateRelease(projectRoot, themeName string, index *releaseIndex, id string) error {
+	if index.find(id) < 0 {
+		return fmt.Errorf("release '%s' does not exist", id)
+	}
+
+	releaseDir := filepath.Join(getReleasesDir(projectRoot, themeName), id)
+	liveDir := getLiveDir(projectRoot, themeName)
+	stagingDir := filepath.Join(filepath.Dir(liveDir), ".next-"+themeName)
+
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return err
+	}
+	if err := utils.CopyDirectory(releaseDir, stagingDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("failed to stage release '%s': %v", id, err)
+	}
+	if err := utils.SwapDirectory(stagingDir, liveDir); err != nil {
+		os.RemoveAll(stagingDir)
+		return fmt.Errorf("failed to switch '%s' to release '%s': %v", liveDir, id, err)
+	}
+
+	// 交换后临时目录中是原来上线的内容
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return err
+	}
+	if err := installNginxConfig(projectRoot, themeName, id); err != nil {
+		return fmt.Errorf("failed to install nginx config of release '%s': %v", id, err)
+	}
+
+	index.Current = id
+	return saveReleaseIndex(projectRoot, themeName, index)
+}
+
+// pruneReleases 只保留最近的 keep 个版本，当前上线的版本总是保留
+func pruneReleases(projectRoot, themeName string, index *releaseIndex, keep int) error {
+	if keep < 1 {
+		keep = 1
+	}
+
+	var kept []releaseInfo
+	for i, release := range index.Releases {
+		if i >= len(index.Releases)-keep || release.ID == index.Current {
+			kept = append(kept, release)
+			continue
+		}
+		if err := removeRelease(projectRoot, themeName, release.ID); err != nil {
+			return err
+		}
+	}
+
+	index.Releases = kept
+	return saveReleaseIndex(projectRoot, themeName, index)
+}
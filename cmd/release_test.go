@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPruneReleases(t *testing.T) {
+	projectRoot := t.TempDir()
+	index := &releaseIndex{Current: "r2"}
+	for _, id := range []string{"r1", "r2", "r3", "r4", "r5"} {
+		index.Releases = append(index.Releases, releaseInfo{ID: id})
+		if err := os.MkdirAll(filepath.Join(getReleasesDir(projectRoot, "t"), id), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 保留最近的两个版本，当前上线的 r2 虽然较旧也保留
+	if err := pruneReleases(projectRoot, "t", index, 2); err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, release := range index.Releases {
+		kept = append(kept, release.ID)
+	}
+	if want := []string{"r2", "r4", "r5"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+	for id, exists := range map[string]bool{"r1": false, "r2": true, "r3": false, "r4": true, "r5": true} {
+		if _, err := os.Stat(filepath.Join(getReleasesDir(projectRoot, "t"), id)); (err == nil) != exists {
+			t.Errorf("release %s: exists = %v, want %v", id, err == nil, exists)
+		}
+	}
+
+	saved, err := loadReleaseIndex(projectRoot, "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.Releases, index.Releases) {
+		t.Errorf("saved index %v does not match %v", saved.Releases, index.Releases)
+	}
+
+	// keep 小于 1 时至少保留一个版本
+	if err := pruneReleases(projectRoot, "t", index, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Releases) != 2 || index.Releases[0].ID != "r2" || index.Releases[1].ID != "r5" {
+		t.Errorf("unexpected releases after pruning to one: %v", index.Releases)
+	}
+}
+
+func TestActivateRelease(t *testing.T) {
+	projectRoot := t.TempDir()
+	index := &releaseIndex{}
+	for _, id := range []string{"r1", "r2"} {
+		index.Releases = append(index.Releases, releaseInfo{ID: id})
+		dir := filepath.Join(getReleasesDir(projectRoot, "t"), id)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(id), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range []string{"r1", "r2", "r1"} {
+		if err := activateRelease(projectRoot, "t", index, id); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(getLiveDir(projectRoot, "t"), "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != id || index.Current != id {
+			t.Errorf("expected %s to be live, got %q (current %s)", id, data, index.Current)
+		}
+		// 上线目录必须是真实目录，git 提交 .deploy 时才会包含站点文件
+		if info, err := os.Lstat(getLiveDir(projectRoot, "t")); err != nil || !info.IsDir() {
+			t.Errorf("expected %s to be a real directory", getLiveDir(projectRoot, "t"))
+		}
+	}
+	if err := activateRelease(projectRoot, "t", index, "r3"); err == nil {
+		t.Error("expected an error for a missing release")
+	}
+}
+
+func TestMayUseSharedOutDir(t *testing.T) {
+	projectRoot := t.TempDir()
+	build := &themeBuild{Name: "t", ProjectRoot: projectRoot, OutDir: filepath.Join(projectRoot, ".deploy", ".temp-t")}
+
+	// 没有构建过的主题可能输出到 .deploy/.temp
+	if !mayUseSharedOutDir(build) {
+		t.Error("expected a theme without releases to be treated as shared")
+	}
+
+	for outDir, shared := range map[string]bool{".deploy/.temp": true, ".deploy/.temp-t": false} {
+		index := &releaseIndex{Releases: []releaseInfo{{ID: "r1", OutDir: outDir}}}
+		if err := saveReleaseIndex(projectRoot, "t", index); err != nil {
+			t.Fatal(err)
+		}
+		if got := mayUseSharedOutDir(build); got != shared {
+			t.Errorf("last release from %s: shared = %v, want %v", outDir, got, shared)
+		}
+	}
+
+	// 声明了输出目录的主题不会使用 .deploy/.temp
+	build.OutDirSet = true
+	if mayUseSharedOutDir(build) {
+		t.Error("expected a theme with a declared output directory not to be shared")
+	}
+}
+
+func TestActivateReleaseNginxConfig(t *testing.T) {
+	projectRoot := t.TempDir()
+	index := &releaseIndex{}
+	for _, id := range []string{"r1", "r2"} {
+		index.Releases = append(index.Releases, releaseInfo{ID: id})
+		if err := os.MkdirAll(filepath.Join(getReleasesDir(projectRoot, "t"), id), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 只有 r1 生成了 nginx 配置片段
+	if err := os.WriteFile(getReleaseNginxConfigPath(projectRoot, "t", "r1"), []byte("r1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := activateRelease(projectRoot, "t", index, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(getNginxConfigPath(projectRoot, "t")); err != nil || string(data) != "r1" {
+		t.Errorf("expected the nginx config of r1, got %q (%v)", data, err)
+	}
+
+	if err := activateRelease(projectRoot, "t", index, "r2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(getNginxConfigPath(projectRoot, "t")); !os.IsNotExist(err) {
+		t.Error("expected the nginx config to be removed for a release without one")
+	}
+
+	// 清理旧版本时一起删除配置片段
+	if err := pruneReleases(projectRoot, "t", index, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(getReleaseNginxConfigPath(projectRoot, "t", "r1")); !os.IsNotExist(err) {
+		t.Error("expected the nginx config of a pruned release to be removed")
+	}
+}
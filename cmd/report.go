@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"wordma-cli/utils"
+)
+
+// reportLargestFiles 是报告中每种类型列出的最大文件数量
+const reportLargestFiles = 5
+
+// reportListedChanges 是输出中每类变化最多列出的文件数量，JSON 报告中包含全部文件
+const reportListedChanges = 10
+
+// assetTypes 将文件扩展名归类为资源类型
+var assetTypes = map[string]string{
+	".html": "html", ".htm": "html",
+	".css": "css",
+	".js":  "js", ".mjs": "js", ".cjs": "js",
+	".png": "image", ".jpg": "image", ".jpeg": "image", ".gif": "image",
+	".svg": "image", ".webp": "image", ".avif": "image", ".ico": "image",
+	".woff": "font", ".woff2": "font", ".ttf": "font", ".otf": "font", ".eot": "font",
+	".json": "data", ".xml": "data", ".txt": "data", ".map": "sourcemap",
+}
+
+// reportFile 是报告中的一个输出文件
+type reportFile struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Size     int64  `json:"size"`
+	GzipSize int64  `json:"gzipSize"`
+	hash     string
+}
+
+// reportTypeSummary 是同一类型文件的汇总和其中最大的文件
+type reportTypeSummary struct {
+	Type     string       `json:"type"`
+	Files    int          `json:"files"`
+	Size     int64        `json:"size"`
+	GzipSize int64        `json:"gzipSize"`
+	Largest  []reportFile `json:"largest"`
+}
+
+// reportChange 是与上一次构建相比内容发生变化的文件
+type reportChange struct {
+	Path    string `json:"path"`
+	OldSize int64  `json:"oldSize"`
+	NewSize int64  `json:"newSize"`
+}
+
+// reportDiff 是与上一次构建输出的差异
+type reportDiff struct {
+	PreviousRelease string         `json:"previousRelease"`
+	Added           []string       `json:"added"`
+	Removed         []string       `json:"removed"`
+	Changed         []reportChange `json:"changed"`
+}
+
+// buildReport 是一次构建输出的统计报告
+type buildReport struct {
+	Theme       string              `json:"theme"`
+	Release     string              `json:"release"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Files       int                 `json:"files"`
+	Size        int64               `json:"size"`
+	GzipSize    int64               `json:"gzipSize"`
+	Types       []reportTypeSummary `json:"types"`
+	Diff        *reportDiff         `json:"diff,omitempty"`
+
+	files map[string]*reportFile
+}
+
+// getReportPath 返回主题最近一次构建报告的路径
+func getReportPath(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "reports", themeName+".json")
+}
+
+// assetType 返回文件的资源类型
+func assetType(path string) string {
+	if t, ok := assetTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return t
+	}
+	return "other"
+}
+
+// scanOutputFiles 统计目录中每个文件的大小、压缩后大小和内容哈希
+func scanOutputFiles(dir string) (map[string]*reportFile, error) {
+	files := make(map[string]*reportFile)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 预压缩生成的 .gz/.br 文件不计入统计
+		if !info.Mode().IsRegular() || utils.IsPrecompressedFile(path) {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		gzipSize, err := utils.GzipSize(path)
+		if err != nil {
+			return err
+		}
+		hash, err := utils.HashFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+		files[relPath] = &reportFile{
+			Path:     relPath,
+			Type:     assetType(relPath),
+			Size:     info.Size(),
+			GzipSize: gzipSize,
+			hash:     hash,
+		}
+		return nil
+	})
+	return files, err
+}
+
+// newBuildReport 统计 outputDir 的内容，previousDir 不为空时计算与其的差异
+func newBuildReport(themeName, release, outputDir, previousRelease, previousDir string) (*buildReport, error) {
+	files, err := scanOutputFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &buildReport{Theme: themeName, Release: release, GeneratedAt: time.Now(), Files: len(files), files: files}
+
+	types := make(map[string]*reportTypeSummary)
+	for _, file := range files {
+		report.Size += file.Size
+		report.GzipSize += file.GzipSize
+
+		summary := types[file.Type]
+		if summary == nil {
+			summary = &reportTypeSummary{Type: file.Type}
+			types[file.Type] = summary
+		}
+		summary.Files++
+		summary.Size += file.Size
+		summary.GzipSize += file.GzipSize
+		summary.Largest = append(summary.Largest, *file)
+	}
+
+	for _, summary := range types {
+		largest := summary.Largest
+		sort.Slice(largest, func(i, j int) bool {
+			if largest[i].Size != largest[j].Size {
+				return largest[i].Size > largest[j].Size
+			}
+			return largest[i].Path < largest[j].Path
+		})
+		if len(largest) > reportLargestFiles {
+			summary.Largest = largest[:reportLargestFiles]
+		}
+		report.Types = append(report.Types, *summary)
+	}
+	sort.Slice(report.Types, func(i, j int) bool { return report.Types[i].Size > report.Types[j].Size })
+
+	if previousDir != "" && utils.FileExists(previousDir) {
+		previous, err := scanOutputFiles(previousDir)
+		if err != nil {
+			return nil, err
+		}
+		report.Diff = diffOutputFiles(previous, files)
+		report.Diff.PreviousRelease = previousRelease
+	}
+
+	return report, nil
+}
+
+// diffOutputFiles 比较两次构建输出的文件
+func diffOutputFiles(previous, current map[string]*reportFile) *reportDiff {
+	diff := &reportDiff{Added: []string{}, Removed: []string{}, Changed: []reportChange{}}
+	for path, file := range current {
+		old, ok := previous[path]
+		if !ok {
+			diff.Added = append(diff.Added, path)
+		} else if old.hash != file.hash {
+			diff.Changed = append(diff.Changed, reportChange{Path: path, OldSize: old.Size, NewSize: file.Size})
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Path < diff.Changed[j].Path })
+	return diff
+}
+
+// saveBuildReport 将报告保存为 JSON
+func saveBuildReport(path string, report *buildReport) error {
+	if err := utils.CreateDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// printBuildReport 以表格形式输出报告
+func printBuildReport(w io.Writer, report *buildReport) {
+	fmt.Fprintf(w, "\n%d files, %s (%s gzipped)\n\n", report.Files, utils.FormatSize(report.Size), utils.FormatSize(report.GzipSize))
+
+	fmt.Fprintf(w, "  %-10s %6s %10s %10s\n", "TYPE", "FILES", "SIZE", "GZIP")
+	for _, summary := range report.Types {
+		fmt.Fprintf(w, "  %-10s %6d %10s %10s\n", summary.Type, summary.Files,
+			utils.FormatSize(summary.Size), utils.FormatSize(summary.GzipSize))
+	}
+
+	// 按类型列出最大的文件，类型名只在每组的第一行显示
+	if len(report.Types) > 0 {
+		fmt.Fprintf(w, "\n  %-10s %10s %10s  %s\n", "LARGEST", "SIZE", "GZIP", "FILE")
+		for _, summary := range report.Types {
+			for i, file := range summary.Largest {
+				label := ""
+				if i == 0 {
+					label = summary.Type
+				}
+				fmt.Fprintf(w, "  %-10s %10s %10s  %s\n", label,
+					utils.FormatSize(file.Size), utils.FormatSize(file.GzipSize), file.Path)
+			}
+		}
+	}
+
+	if report.Diff == nil {
+		fmt.Fprintln(w)
+		return
+	}
+
+	diff := report.Diff
+	fmt.Fprintf(w, "\nChanges since release '%s': %s added, %s removed, %s changed\n", diff.PreviousRelease,
+		utils.ColorText(fmt.Sprint(len(diff.Added)), "green"),
+		utils.ColorText(fmt.Sprint(len(diff.Removed)), "red"),
+		utils.ColorText(fmt.Sprint(len(diff.Changed)), "yellow"))
+
+	printChangedPaths(w, "+", "green", diff.Added)
+	printChangedPaths(w, "-", "red", diff.Removed)
+	var changed []string
+	for _, change := range diff.Changed {
+		changed = append(changed, fmt.Sprintf("%s (%s → %s)", change.Path,
+			utils.FormatSize(change.OldSize), utils.FormatSize(change.NewSize)))
+	}
+	printChangedPaths(w, "~", "yellow", changed)
+	fmt.Fprintln(w)
+}
+
+// printChangedPaths 输出一类变化的文件，超过 reportListedChanges 个时省略
+func printChangedPaths(w io.Writer, marker, colorName string, paths []string) {
+	for i, path := range paths {
+		if i == reportListedChanges {
+			fmt.Fprintf(w, "  %s ... and %d more\n", utils.ColorText(marker, colorName), len(paths)-i)
+			break
+		}
+		fmt.Fprintf(w, "  %s %s\n", utils.ColorText(marker, colorName), path)
+	}
+}
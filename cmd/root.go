@@ -41,4 +41,6 @@ func init() {
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(themeCmd)
+	rootCmd.AddCommand(checkCmd)
 }
\ No newline at end of file
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wordma-cli/utils"
+)
+
+// defaultSearchIndex 是默认的搜索索引文件名
+const defaultSearchIndex = "search-index.json"
+
+// defaultSearchMaxText 是每个页面默认保留的正文字符数
+const defaultSearchMaxText = 5000
+
+// searchIndexVersion 是索引格式的版本，格式变化时递增
+const searchIndexVersion = 1
+
+// searchSettings 是一次构建使用的搜索索引设置
+type searchSettings struct {
+	Output  string
+	Exclude []string
+	MaxText int
+}
+
+// searchIndex 是写入输出目录的搜索索引
+type searchIndex struct {
+	Version int          `json:"version"`
+	Pages   []searchPage `json:"pages"`
+}
+
+// searchPage 是索引中的一个页面
+type searchPage struct {
+	URL string `json:"url"`
+	utils.SearchDocument
+}
+
+// resolveSearch 根据 --search-index、项目配置和主题清单确定搜索索引设置，不生成时返回 nil。
+// 主题在清单中声明 wordma.searchIndex 时默认生成
+func resolveSearch(config *SearchConfig, manifest *ThemeManifest, flag, flagSet bool) *searchSettings {
+	if config == nil {
+		config = &SearchConfig{}
+		if !flagSet && manifest.SearchIndex == "" {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+
+	settings := &searchSettings{
+		Output:  firstNonEmpty(config.Output, manifest.SearchIndex, defaultSearchIndex),
+		Exclude: append([]string{"404.html", "404/index.html"}, config.Exclude...),
+		MaxText: config.MaxText,
+	}
+	settings.Output = filepath.ToSlash(filepath.Clean(settings.Output))
+	if settings.MaxText <= 0 {
+		settings.MaxText = defaultSearchMaxText
+	}
+	return settings
+}
+
+// generateSearchIndex 提取输出中每个页面的标题、小标题和正文，写入紧凑的 JSON 索引
+func (b *themeBuild) generateSearchIndex(outDir string) error {
+	settings := b.Search
+	if strings.HasPrefix(settings.Output, "../") || filepath.IsAbs(settings.Output) {
+		return fmt.Errorf("search index must be written inside the output directory, got '%s'", settings.Output)
+	}
+	indexPath := filepath.Join(outDir, filepath.FromSlash(settings.Output))
+	if utils.FileExists(indexPath) {
+		utils.FprintWarning(b.Out, fmt.Sprintf("Build output already contains '%s', leaving it unchanged", settings.Output))
+		return nil
+	}
+
+	index := searchIndex{Version: searchIndexVersion, Pages: []searchPage{}}
+	err := filepath.Walk(outDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || assetType(file) != "html" {
+			return nil
+		}
+		relPath, err := filepath.Rel(outDir, file)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if matchesAny(settings.Exclude, relPath) {
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		// 不收录的页面和跳转页面不加入索引
+		if meta := utils.ParsePageMeta(data); meta.NoIndex() || meta.Refresh != "" {
+			return nil
+		}
+		doc, err := utils.ExtractSearchDocument(data, settings.MaxText)
+		if err != nil {
+			return fmt.Errorf("%s: %v", relPath, err)
+		}
+		if doc.Title == "" && len(doc.Headings) > 0 {
+			doc.Title = doc.Headings[0]
+		}
+		index.Pages = append(index.Pages, searchPage{URL: b.BasePath + pageURLPath(relPath), SearchDocument: doc})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build search index: %v", err)
+	}
+
+	// 不转义 HTML 字符，索引更紧凑
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(index); err != nil {
+		return err
+	}
+	if err := utils.CreateDir(filepath.Dir(indexPath)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(indexPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %v", err)
+	}
+
+	utils.FprintInfo(b.Out, fmt.Sprintf("Search index with %d page(s) written to '%s' (%s)",
+		len(index.Pages), settings.Output, utils.FormatSize(int64(buf.Len()))))
+	return nil
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"wordma-cli/utils"
+)
+
+// defaultSitemapExclude 是默认不写入 sitemap 的页面
+var defaultSitemapExclude = []string{"404.html", "404/index.html"}
+
+// sitemapSettings 是一次构建使用的 sitemap 设置
+type sitemapSettings struct {
+	Exclude []string
+	Robots  bool
+}
+
+// resolveSitemap 根据项目配置和 --sitemap 确定 sitemap 设置，不生成时返回 nil
+func resolveSitemap(config *SitemapConfig, flag, flagSet bool) *sitemapSettings {
+	if config == nil {
+		config = &SitemapConfig{}
+		if !flagSet {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+	return &sitemapSettings{
+		Exclude: append(append([]string{}, defaultSitemapExclude...), config.Exclude...),
+		Robots:  config.Robots == nil || *config.Robots,
+	}
+}
+
+// siteRootURL 返回站点根目录的地址（以 / 结尾）；baseUrl 只有主机时加上基础路径
+func siteRootURL(baseURL, basePath string) (*url.URL, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("the site address is unknown, set baseUrl in %s or WORDMA_BASE_URL", projectConfigFile)
+	}
+	root, err := url.Parse(baseURL)
+	if err != nil || root.Host == "" || (root.Scheme != "http" && root.Scheme != "https") {
+		return nil, fmt.Errorf("invalid site address '%s', expected an absolute http(s) URL", baseURL)
+	}
+	if root.Path == "" || root.Path == "/" {
+		root.Path = basePath
+	}
+	if !strings.HasSuffix(root.Path, "/") {
+		root.Path += "/"
+	}
+	root.RawPath, root.RawQuery, root.Fragment = "", "", ""
+	return root, nil
+}
+
+// pageURLPath 返回 HTML 文件对应的 URL 路径，index.html 对应所在目录
+func pageURLPath(relPath string) string {
+	if relPath == "index.html" {
+		return ""
+	}
+	if strings.HasSuffix(relPath, "/index.html") {
+		return strings.TrimSuffix(relPath, "index.html")
+	}
+	return relPath
+}
+
+// generateSitemap 根据输出中的 HTML 页面生成 sitemap.xml 和 robots.txt，主题已生成的文件保持不变
+func (b *themeBuild) generateSitemap(outDir string) error {
+	root, err := siteRootURL(b.WordmaEnv.BaseURL, b.BasePath)
+	if err != nil {
+		return fmt.Errorf("cannot generate sitemap: %v", err)
+	}
+
+	sitemapPath := filepath.Join(outDir, "sitemap.xml")
+	if utils.FileExists(sitemapPath) {
+		utils.FprintInfo(b.Out, "Build output already contains sitemap.xml, leaving it unchanged")
+	} else {
+		urls, skipped, err := b.collectSitemapURLs(outDir, root)
+		if err != nil {
+			return fmt.Errorf("failed to collect pages for sitemap: %v", err)
+		}
+		files, err := utils.WriteSitemap(outDir, root.String(), urls, utils.SitemapMaxURLs)
+		if err != nil {
+			return fmt.Errorf("failed to write sitemap: %v", err)
+		}
+
+		message := fmt.Sprintf("Sitemap with %d page(s) written to sitemap.xml", len(urls))
+		if len(files) > 1 {
+			message += fmt.Sprintf(" (index of %d sitemaps)", len(files)-1)
+		}
+		if skipped > 0 {
+			message += fmt.Sprintf(", %d page(s) skipped (noindex or canonical on another site)", skipped)
+		}
+		utils.FprintInfo(b.Out, message)
+	}
+
+	robotsPath := filepath.Join(outDir, "robots.txt")
+	if !b.Sitemap.Robots {
+		return nil
+	}
+	if utils.FileExists(robotsPath) {
+		utils.FprintInfo(b.Out, "Build output already contains robots.txt, leaving it unchanged")
+		return nil
+	}
+	robots := fmt.Sprintf("User-agent: *\nAllow: /\n\nSitemap: %ssitemap.xml\n", root.String())
+	if err := os.WriteFile(robotsPath, []byte(robots), 0644); err != nil {
+		return fmt.Errorf("failed to write robots.txt: %v", err)
+	}
+	if b.BasePath != "/" {
+		utils.FprintWarning(b.Out, fmt.Sprintf("robots.txt is only read at the root of %s, copy it there when the site is served from '%s'", root.Host, b.BasePath))
+	}
+	return nil
+}
+
+// collectSitemapURLs 读取每个 HTML 页面的规范地址和修改时间，返回页面列表和跳过的页面数
+func (b *themeBuild) collectSitemapURLs(outDir string, root *url.URL) ([]utils.SitemapURL, int, error) {
+	seen := make(map[string]bool)
+	var urls []utils.SitemapURL
+	skipped := 0
+
+	err := filepath.Walk(outDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || assetType(path) != "html" {
+			return nil
+		}
+		relPath, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		for _, pattern := range b.Sitemap.Exclude {
+			if utils.MatchGlob(pattern, relPath) {
+				return nil
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		meta := utils.ParsePageMeta(data)
+		if meta.NoIndex() {
+			skipped++
+			return nil
+		}
+
+		loc := root.ResolveReference(&url.URL{Path: pageURLPath(relPath)})
+		if meta.Canonical != "" {
+			canonical, err := loc.Parse(meta.Canonical)
+			if err == nil && canonical.Host != root.Host {
+				skipped++
+				return nil
+			}
+			if err == nil {
+				loc = canonical
+			}
+		}
+		if seen[loc.String()] {
+			return nil
+		}
+		seen[loc.String()] = true
+
+		lastMod := info.ModTime().Format(time.RFC3339)
+		for _, value := range []string{meta.Modified, meta.Published} {
+			if _, ok := utils.ParseLastMod(value); ok {
+				lastMod = value
+				break
+			}
+		}
+		urls = append(urls, utils.SitemapURL{Loc: loc.String(), LastMod: lastMod})
+		return nil
+	})
+
+	sort.Slice(urls, func(i, j int) bool { return urls[i].Loc < urls[j].Loc })
+	return urls, skipped, err
+}
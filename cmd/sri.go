@@ -0,0 +1,496 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"wordma-cli/utils"
+)
+
+// defaultSRIAlgorithm 是默认的 integrity 哈希算法
+const defaultSRIAlgorithm = "sha384"
+
+// maxListedItems 是 printLimitedList 最多列出的项数
+const maxListedItems = 10
+
+// sriFetchTimeout 是下载单个 CDN 资源的超时时间
+const sriFetchTimeout = 15 * time.Second
+
+// sriSettings 是一次构建使用的 integrity 设置
+type sriSettings struct {
+	Algorithm string
+	CDN       bool
+	CSP       bool
+	Exclude   []string
+}
+
+// resolveSRI 根据项目配置和 --sri 确定 integrity 设置，不生成时返回 nil
+func resolveSRI(config *SRIConfig, flag, flagSet bool) *sriSettings {
+	if config == nil {
+		config = &SRIConfig{}
+		if !flagSet {
+			return nil
+		}
+	}
+	if !isStepEnabled(config.Enabled, flag, flagSet) {
+		return nil
+	}
+	return &sriSettings{
+		Algorithm: firstNonEmpty(strings.ToLower(config.Algorithm), defaultSRIAlgorithm),
+		CDN:       config.CDN == nil || *config.CDN,
+		CSP:       config.CSP == nil || *config.CSP,
+		Exclude:   config.Exclude,
+	}
+}
+
+// getSRICachePath 返回 CDN 资源 integrity 缓存的路径；固定版本的资源内容不变，缓存不过期
+func getSRICachePath(projectRoot string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "cache", "sri.json")
+}
+
+// getCSPPath 返回 CSP 建议的保存路径
+func getCSPPath(projectRoot, themeName string) string {
+	return filepath.Join(utils.GetStateDir(projectRoot), "reports", themeName+".csp.txt")
+}
+
+// urlAttributeNames 是可能包含 javascript: 链接的属性
+var urlAttributeNames = map[string]bool{"href": true, "src": true, "action": true, "formaction": true}
+
+// integrityPass 保存为输出添加 integrity 和收集 CSP 来源时的状态
+type integrityPass struct {
+	b        *themeBuild
+	outDir   string
+	resolver utils.SiteResolver
+	local    map[string]string // 站内文件 -> integrity
+	remote   map[string]string // "算法 URL" -> integrity，保存为缓存
+	failed   map[string]string // 下载失败的 CDN 地址 -> 原因
+	unpinned map[string]bool   // 没有固定版本、不能添加 integrity 的外部地址
+	csp      *utils.CSP
+	handlers map[string]bool // 使用内联事件处理器的页面
+	jsLinks  map[string]bool // 使用 javascript: 链接的页面
+	styled   bool            // 是否有 style 属性
+	tags     int
+	files    int
+}
+
+// addIntegrity 为输出中的站内和固定版本 CDN 的脚本、样式表添加 integrity 属性，并根据找到的来源和内联内容生成 CSP 建议
+func (b *themeBuild) addIntegrity(outDir string) error {
+	if _, err := utils.IntegrityHash(nil, b.SRI.Algorithm); err != nil {
+		return err
+	}
+
+	p := &integrityPass{
+		b:        b,
+		outDir:   outDir,
+		resolver: utils.SiteResolver{BasePath: b.BasePath},
+		local:    make(map[string]string),
+		remote:   make(map[string]string),
+		failed:   make(map[string]string),
+		unpinned: make(map[string]bool),
+		csp:      utils.NewCSP(),
+		handlers: make(map[string]bool),
+		jsLinks:  make(map[string]bool),
+	}
+	if u, err := url.Parse(b.WordmaEnv.BaseURL); err == nil {
+		p.resolver.SiteHost = u.Host
+	}
+	cachePath := getSRICachePath(b.ProjectRoot)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(data, &p.remote)
+	}
+
+	err := filepath.Walk(outDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(outDir, file)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		switch assetType(file) {
+		case "html":
+			if matchesAny(b.SRI.Exclude, relPath) {
+				return nil
+			}
+			return p.processPage(file, relPath, info)
+		case "css":
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			p.collectCSS(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add integrity attributes: %v", err)
+	}
+
+	if data, err := json.MarshalIndent(p.remote, "", "  "); err == nil && len(p.remote) > 0 {
+		if err := utils.CreateDir(filepath.Dir(cachePath)); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+
+	utils.FprintInfo(b.Out, fmt.Sprintf("Added integrity to %d tag(s) in %d file(s)", p.tags, p.files))
+	p.printWarnings()
+	if b.SRI.CSP {
+		b.CSP = p.policy()
+	}
+	return nil
+}
+
+// processPage 为页面中的标签添加 integrity 并收集 CSP 来源，页面有变化时写回
+func (p *integrityPass) processPage(file, relPath string, info os.FileInfo) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	fromDir := path.Dir(relPath)
+	tags := 0
+	inScript, inStyle, inMedia := false, false, 0
+	result := utils.RewriteHTMLTokens(data, func(tt html.TokenType, tag string, raw []byte) []byte {
+		switch tt {
+		case html.TextToken:
+			if inScript && len(raw) > 0 {
+				p.csp.Add("script-src", utils.CSPHash(raw))
+			}
+			if inStyle && len(raw) > 0 {
+				p.csp.Add("style-src", utils.CSPHash(raw))
+				p.collectCSS(raw)
+			}
+			return raw
+		case html.EndTagToken:
+			switch tag {
+			case "script":
+				inScript = false
+			case "style":
+				inStyle = false
+			case "video", "audio":
+				if inMedia > 0 {
+					inMedia--
+				}
+			}
+			return raw
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			return raw
+		}
+
+		attrs := utils.TagAttributes(raw)
+		p.collectAttributes(relPath, attrs)
+		attr := func(name string) string {
+			return strings.TrimSpace(attrs[name])
+		}
+
+		directive, resource := "", ""
+		switch tag {
+		case "script":
+			if src := attr("src"); src != "" {
+				directive, resource = "script-src", src
+			} else {
+				inScript = tt == html.StartTagToken && isExecutableScript(attr("type"))
+			}
+		case "style":
+			inStyle = tt == html.StartTagToken
+		case "link":
+			rel, as := strings.ToLower(attr("rel")), strings.ToLower(attr("as"))
+			href := attr("href")
+			switch {
+			case utils.HasToken(rel, "stylesheet") || (utils.HasToken(rel, "preload") && as == "style"):
+				directive, resource = "style-src", href
+			case utils.HasToken(rel, "modulepreload") || (utils.HasToken(rel, "preload") && as == "script"):
+				directive, resource = "script-src", href
+			case utils.HasToken(rel, "preload") && as == "font":
+				p.addSource("font-src", href)
+			case utils.HasToken(rel, "icon") || utils.HasToken(rel, "apple-touch-icon") || (utils.HasToken(rel, "preload") && as == "image"):
+				p.addSource("img-src", href)
+			case utils.HasToken(rel, "manifest"):
+				p.addSource("manifest-src", href)
+			}
+		case "img", "input":
+			if tag == "img" || strings.EqualFold(attr("type"), "image") {
+				p.addSource("img-src", attr("src"))
+				p.addSrcset(attr("srcset"))
+			}
+		case "source":
+			if inMedia > 0 {
+				p.addSource("media-src", attr("src"))
+			} else {
+				p.addSrcset(attr("srcset"))
+			}
+		case "video", "audio":
+			if tt == html.StartTagToken {
+				inMedia++
+			}
+			p.addSource("media-src", attr("src"))
+			p.addSource("img-src", attr("poster"))
+		case "track":
+			p.addSource("media-src", attr("src"))
+		case "iframe":
+			p.addSource("frame-src", attr("src"))
+		case "form":
+			p.addSource("form-action", attr("action"))
+		}
+		if resource == "" {
+			return raw
+		}
+
+		p.addSource(directive, resource)
+		if _, ok := attrs["integrity"]; ok {
+			return raw
+		}
+		integrity, external := p.integrity(resource, fromDir)
+		if integrity == "" {
+			return raw
+		}
+		raw = utils.AddTagAttribute(raw, "integrity", integrity)
+		if _, ok := attrs["crossorigin"]; external && !ok {
+			raw = utils.AddTagAttribute(raw, "crossorigin", "anonymous")
+		}
+		tags++
+		return raw
+	})
+
+	if tags == 0 {
+		return nil
+	}
+	p.tags += tags
+	p.files++
+	return os.WriteFile(file, result, info.Mode().Perm())
+}
+
+// collectAttributes 记录标签中的内联事件处理器、javascript: 链接和 style 属性
+func (p *integrityPass) collectAttributes(relPath string, attrs map[string]string) {
+	for name, value := range attrs {
+		switch {
+		case strings.HasPrefix(name, "on"):
+			p.handlers[relPath] = true
+		case name == "style" && strings.TrimSpace(value) != "":
+			p.styled = true
+			p.collectCSS([]byte(value))
+		case urlAttributeNames[name] && strings.HasPrefix(strings.ToLower(strings.TrimSpace(value)), "javascript:"):
+			p.jsLinks[relPath] = true
+		}
+	}
+}
+
+// integrity 返回资源的 integrity 值，第二个返回值表示资源是否在其他站点；不能确定时返回空字符串
+func (p *integrityPass) integrity(ref, fromDir string) (string, bool) {
+	if target, _, ok := p.resolver.Resolve(ref, fromDir); ok {
+		if target == "" || strings.HasSuffix(target, "/") {
+			return "", false
+		}
+		if integrity, ok := p.local[target]; ok {
+			return integrity, false
+		}
+		data, err := os.ReadFile(filepath.Join(p.outDir, filepath.FromSlash(target)))
+		if err != nil {
+			return "", false
+		}
+		integrity, _ := utils.IntegrityHash(data, p.b.SRI.Algorithm)
+		p.local[target] = integrity
+		return integrity, false
+	}
+
+	if _, ok := utils.CSPSource(ref); !ok || !strings.Contains(ref, "//") {
+		return "", false
+	}
+	rawURL := ref
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	if !utils.IsPinnedURL(rawURL) {
+		p.unpinned[rawURL] = true
+		return "", true
+	}
+	if !p.b.SRI.CDN {
+		return "", true
+	}
+
+	key := p.b.SRI.Algorithm + " " + rawURL
+	if integrity, ok := p.remote[key]; ok {
+		return integrity, true
+	}
+	if _, ok := p.failed[rawURL]; ok {
+		return "", true
+	}
+	integrity, err := utils.FetchIntegrity(rawURL, p.b.SRI.Algorithm, "wordma-cli/"+version, sriFetchTimeout)
+	if err != nil {
+		p.failed[rawURL] = err.Error()
+		return "", true
+	}
+	p.remote[key] = integrity
+	return integrity, true
+}
+
+// addSource 将资源地址对应的来源加入 CSP 指令，站内地址对应 'self'
+func (p *integrityPass) addSource(directive, ref string) {
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return
+	}
+	if source, ok := utils.CSPSource(ref); ok {
+		if u, err := url.Parse(ref); err == nil && p.resolver.SiteHost != "" && strings.EqualFold(u.Host, p.resolver.SiteHost) {
+			source = "'self'"
+		}
+		p.csp.Add(directive, source)
+		return
+	}
+	if strings.Contains(strings.SplitN(ref, "/", 2)[0], ":") {
+		// mailto:、javascript: 等不是资源
+		return
+	}
+	p.csp.Add(directive, "'self'")
+}
+
+// addSrcset 将 srcset 中每个候选图片的来源加入 img-src
+func (p *integrityPass) addSrcset(srcset string) {
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			p.addSource("img-src", fields[0])
+		}
+	}
+}
+
+// collectCSS 按扩展名把 CSS 中 url() 和 @import 引用的来源加入 font-src、style-src 或 img-src
+func (p *integrityPass) collectCSS(data []byte) {
+	utils.RewriteCSSURLs(data, func(ref string) (string, bool) {
+		lower := strings.ToLower(ref)
+		if strings.HasPrefix(lower, "data:") {
+			if strings.HasPrefix(lower, "data:font/") || strings.HasPrefix(lower, "data:application/font") || strings.HasPrefix(lower, "data:application/x-font") {
+				p.csp.Add("font-src", "data:")
+			} else {
+				p.csp.Add("img-src", "data:")
+			}
+			return "", false
+		}
+		ext := path.Ext(strings.SplitN(strings.SplitN(lower, "?", 2)[0], "#", 2)[0])
+		switch ext {
+		case ".woff", ".woff2", ".ttf", ".otf", ".eot":
+			p.addSource("font-src", ref)
+		case ".css":
+			p.addSource("style-src", ref)
+		default:
+			p.addSource("img-src", ref)
+		}
+		return "", false
+	})
+}
+
+// policy 返回收集到的来源组成的 CSP
+func (p *integrityPass) policy() string {
+	p.csp.Add("default-src", "'self'")
+	p.csp.Add("script-src", "'self'")
+	p.csp.Add("style-src", "'self'")
+	p.csp.Add("img-src", "'self'")
+	p.csp.Add("font-src", "'self'")
+	p.csp.Add("connect-src", "'self'")
+	p.csp.Add("object-src", "'none'")
+	p.csp.Add("base-uri", "'self'")
+	p.csp.Add("form-action", "'self'")
+	p.csp.Add("frame-ancestors", "'self'")
+	// 属性中的内联代码无法用哈希允许，只放宽对应的 -attr 指令
+	if len(p.handlers) > 0 {
+		p.csp.Add("script-src-attr", "'unsafe-inline'")
+	}
+	if p.styled {
+		p.csp.Add("style-src-attr", "'unsafe-inline'")
+	}
+	return p.csp.String()
+}
+
+// printWarnings 输出不能添加 integrity 的资源和需要放宽 CSP 的页面
+func (p *integrityPass) printWarnings() {
+	out := p.b.Out
+	if len(p.unpinned) > 0 {
+		utils.FprintWarning(out, fmt.Sprintf("%d external resource(s) have no pinned version, integrity was not added:", len(p.unpinned)))
+		printLimitedList(out, sortedSet(p.unpinned))
+	}
+	if len(p.failed) > 0 {
+		utils.FprintWarning(out, fmt.Sprintf("Failed to download %d CDN resource(s), integrity was not added:", len(p.failed)))
+		var lines []string
+		for u, reason := range p.failed {
+			lines = append(lines, fmt.Sprintf("%s (%s)", u, reason))
+		}
+		sort.Strings(lines)
+		printLimitedList(out, lines)
+	}
+	if p.b.SRI.CSP && len(p.handlers) > 0 {
+		utils.FprintWarning(out, fmt.Sprintf("%d page(s) use inline event handlers, the suggested CSP allows them with script-src-attr 'unsafe-inline':", len(p.handlers)))
+		printLimitedList(out, sortedSet(p.handlers))
+	}
+	if p.b.SRI.CSP && len(p.jsLinks) > 0 {
+		utils.FprintWarning(out, fmt.Sprintf("%d page(s) use javascript: URLs, which the suggested CSP blocks:", len(p.jsLinks)))
+		printLimitedList(out, sortedSet(p.jsLinks))
+	}
+}
+
+// saveCSP 保存 CSP 建议，分别给出 _headers 和 nginx 的写法，失败不影响构建结果
+func (b *themeBuild) saveCSP() {
+	content := fmt.Sprintf(`# Content-Security-Policy suggested by wordma build for theme '%s'
+# Review it before use: origins that are only requested at runtime (fetch, WebSocket,
+# scripts added by other scripts) cannot be found in the build output.
+
+# Netlify / Cloudflare Pages (_headers)
+/*
+  Content-Security-Policy: %s
+
+# nginx
+add_header Content-Security-Policy "%s" always;
+`, b.Name, b.CSP, b.CSP)
+
+	cspPath := getCSPPath(b.ProjectRoot, b.Name)
+	err := utils.CreateDir(filepath.Dir(cspPath))
+	if err == nil {
+		err = os.WriteFile(cspPath, []byte(content), 0644)
+	}
+	if err != nil {
+		utils.FprintWarning(b.Out, fmt.Sprintf("Failed to save suggested CSP: %v", err))
+		return
+	}
+	utils.FprintInfo(b.Out, fmt.Sprintf("Suggested Content-Security-Policy saved to '%s'", b.relPath(cspPath)))
+}
+
+// printLimitedList 逐行输出列表，超过 maxListedItems 项时省略其余项
+func printLimitedList(w io.Writer, lines []string) {
+	for i, line := range lines {
+		if i == maxListedItems {
+			fmt.Fprintf(w, "  ... and %d more\n", len(lines)-i)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
+
+// isExecutableScript 判断 type 属性对应的内联脚本是否会被执行（JSON-LD 等数据块不受 CSP 限制）
+func isExecutableScript(scriptType string) bool {
+	switch strings.ToLower(strings.TrimSpace(scriptType)) {
+	case "", "module", "importmap", "text/javascript", "application/javascript":
+		return true
+	}
+	return false
+}
+
+// sortedSet 返回集合中排序后的值
+func sortedSet(set map[string]bool) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
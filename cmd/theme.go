@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var themeCmd = &cobra.Command{
+	Use:   "theme",
+	Short: "Manage project themes",
+	Long:  "Commands for managing themes in the wordma project",
+}
+
+var themeRollbackCmd = &cobra.Command{
+	Use:   "rollback <name>",
+	Short: "Roll back the last update of a theme",
+	Long:  "Restore a theme to the state recorded before its last 'wordma update theme', including config files and stashed local changes",
+	Args:  cobra.ExactArgs(1),
+	Run:   runThemeRollback,
+}
+
+var themeRollbackYes bool
+
+func init() {
+	themeRollbackCmd.Flags().BoolVarP(&themeRollbackYes, "yes", "y", false, "Skip the confirmation prompt")
+	themeCmd.AddCommand(themeRollbackCmd)
+}
+
+func runThemeRollback(cmd *cobra.Command, args []string) {
+	themeName := args[0]
+
+	// 检查 git 是否安装
+	if !utils.CheckCommand("git") {
+		utils.PrintError("Git is required for rolling back themes")
+		fmt.Printf("  %s\n", utils.GetInstallInstructions("git"))
+		exit(1)
+	}
+
+	// 获取项目根目录
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		exit(1)
+	}
+
+	lock := lockProject(projectRoot, cmd)
+	defer lock.Release()
+
+	// 检查主题目录是否存在
+	themePath := filepath.Join(projectRoot, "themes", themeName)
+	if !utils.FileExists(themePath) {
+		utils.PrintError(fmt.Sprintf("Theme '%s' not found in themes directory", themeName))
+		utils.PrintInfo("Available themes:")
+		listAvailableThemes(projectRoot)
+		exit(1)
+	}
+
+	if !utils.FileExists(filepath.Join(themePath, ".git")) {
+		utils.PrintError(fmt.Sprintf("Theme '%s' is not a git repository", themeName))
+		exit(1)
+	}
+
+	// 先恢复覆盖层修改过的文件：持有覆盖层锁等待正在运行的 dev 或 build，
+	// 避免它们结束时把覆盖层备份复制回回滚后的主题
+	overlayLock, err := lockThemeOverlay(projectRoot, themeName, cmd.CommandPath(), color.Output)
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+	defer overlayLock.Release()
+
+	if err := restoreThemeOverlay(projectRoot, themeName, themePath); err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to restore overridden theme files: %v", err))
+		exit(1)
+	}
+
+	// 读取更新日志
+	journal, err := loadUpdateJournal(projectRoot, themeName)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read update journal: %v", err))
+		exit(1)
+	}
+	if len(journal.Entries) == 0 {
+		utils.PrintError(fmt.Sprintf("No recorded updates found for theme '%s'", themeName))
+		utils.PrintInfo("Only updates made with 'wordma update theme' can be rolled back")
+		exit(1)
+	}
+	entry := journal.Entries[len(journal.Entries)-1]
+
+	utils.PrintInfo(fmt.Sprintf("Rolling back theme '%s' to the state before the update of %s",
+		themeName, entry.CreatedAt.Format("2006-01-02 15:04:05")))
+	fmt.Printf("  Branch: %s\n", entry.Branch)
+	fmt.Printf("  Commit: %s\n", entry.Commit)
+	if entry.StashRef != "" {
+		fmt.Printf("  Stash:  %s\n", entry.StashRef)
+	}
+	if entry.ConfigBackup != "" {
+		fmt.Printf("  Config: %s\n", entry.ConfigBackup)
+	}
+
+	// 回滚会丢弃当前工作区的更改，需要确认
+	if !themeRollbackYes {
+		utils.PrintWarning("Any uncommitted changes in the theme directory will be discarded")
+		fmt.Print("Continue? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
+			utils.PrintInfo("Operation cancelled")
+			return
+		}
+	}
+
+	if err := rollbackUpdate(themePath, entry); err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+	if entry.StashRef != "" {
+		utils.PrintInfo("Stashed local changes restored")
+	}
+	if entry.ConfigBackup != "" {
+		utils.PrintInfo("Configuration files restored")
+	}
+
+	// 从日志中移除已回滚的记录
+	journal.Entries = journal.Entries[:len(journal.Entries)-1]
+	err = saveUpdateJournal(projectRoot, themeName, journal)
+	if err != nil {
+		utils.PrintWarning(fmt.Sprintf("Failed to update journal: %v", err))
+	}
+	discardUpdateJournalEntry(&entry)
+
+	utils.PrintSuccess(fmt.Sprintf("Theme '%s' rolled back successfully!", themeName))
+	utils.PrintInfo("Next steps:")
+	fmt.Printf("  1. wordma install (to restore dependencies if needed)\n")
+	fmt.Printf("  2. wordma dev %s (to test the theme)\n", themeName)
+}
+
+// rollbackUpdate 将主题恢复到更新记录中的状态：切回原分支、重置到原提交，再恢复 stash 的更改和配置目录
+func rollbackUpdate(themePath string, entry UpdateJournalEntry) error {
+	// 切回更新时所在的分支
+	currentBranch, err := getCurrentBranch(themePath)
+	if err == nil && entry.Branch != "" && currentBranch != entry.Branch {
+		if err := utils.RunCommandInDir(themePath, "git", "checkout", entry.Branch); err != nil {
+			return fmt.Errorf("failed to checkout branch '%s': %v", entry.Branch, err)
+		}
+	}
+
+	// 恢复到更新前的提交
+	if err := utils.RunCommandInDir(themePath, "git", "reset", "--hard", entry.Commit); err != nil {
+		return fmt.Errorf("failed to reset theme to %s: %v", entry.Commit, err)
+	}
+
+	// 恢复更新时 stash 的本地更改
+	if entry.StashRef != "" {
+		if err := restoreJournalStash(themePath, entry.StashRef); err != nil {
+			return fmt.Errorf("failed to restore stashed changes: %v, you can try to restore them manually with 'git stash apply %s'", err, entry.StashRef)
+		}
+	}
+
+	// 恢复更新前的配置文件
+	if entry.ConfigBackup != "" {
+		if !utils.FileExists(entry.ConfigBackup) {
+			return fmt.Errorf("config backup '%s' is missing", entry.ConfigBackup)
+		}
+		configPath := filepath.Join(themePath, "config")
+		if err := os.RemoveAll(configPath); err != nil {
+			return fmt.Errorf("failed to remove current config: %v", err)
+		}
+		if err := utils.CopyDirectory(entry.ConfigBackup, configPath); err != nil {
+			return fmt.Errorf("failed to restore config: %v", err)
+		}
+	}
+	return nil
+}
+
+// restoreJournalStash 按哈希应用 stash，若它仍在 stash 列表中则一并删除
+func restoreJournalStash(themePath, stashRef string) error {
+	err := utils.RunCommandInDir(themePath, "git", "stash", "apply", stashRef)
+	if err != nil {
+		return err
+	}
+
+	index, err := findStashIndex(themePath, stashRef)
+	if err != nil || index < 0 {
+		// 用户可能已经手动 pop 过，不需要再删除
+		return nil
+	}
+	return utils.RunCommandInDir(themePath, "git", "stash", "drop", fmt.Sprintf("stash@{%d}", index))
+}
+
+// requireThemePath 获取项目根目录和主题目录，主题不存在时打印可用主题并退出
+func requireThemePath(themeName string) (string, string) {
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
+		exit(1)
+	}
+
+	themePath := filepath.Join(projectRoot, "themes", themeName)
+	if !utils.FileExists(themePath) {
+		utils.PrintError(fmt.Sprintf("Theme '%s' not found in themes directory", themeName))
+		utils.PrintInfo("Available themes:")
+		listAvailableThemes(projectRoot)
+		exit(1)
+	}
+
+	return projectRoot, themePath
+}
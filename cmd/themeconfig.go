@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var themeConfigCmd = &cobra.Command{
+	Use:   "config <name> get|set <dotted.key> [value]",
+	Short: "Get or set a theme configuration value",
+	Long: `Read or change a value in the theme's configuration files (themes/<name>/config).
+The key is looked up across all JSON/YAML files in the config directory. Values passed to
+'set' are parsed as JSON when possible (numbers, booleans, arrays, objects) and treated as
+strings otherwise. Formatting and comments of the edited file are preserved.`,
+	Example: `  wordma theme config my-theme get site.title
+  wordma theme config my-theme set site.title "My Blog"
+  wordma theme config my-theme set pagination.size 10
+  wordma theme config my-theme set nav.links '["/", "/about"]' --file nav.yaml`,
+	Args: cobra.RangeArgs(3, 4),
+	Run:  runThemeConfig,
+}
+
+var (
+	themeConfigFile       string
+	themeConfigNoValidate bool
+)
+
+func init() {
+	themeConfigCmd.Flags().StringVar(&themeConfigFile, "file", "", "Config file (relative to the config directory) to add a new key to")
+	themeConfigCmd.Flags().BoolVar(&themeConfigNoValidate, "no-validate", false, "Skip schema validation when setting a value")
+	themeCmd.AddCommand(themeConfigCmd)
+}
+
+// themeConfigEntry 表示主题 config 目录中的一个配置文件
+type themeConfigEntry struct {
+	Path   string // 相对于主题目录（或覆盖层目录）的路径
+	Source string // 相对于项目根目录的路径，用于提示
+	Data   []byte
+	Value  interface{}
+}
+
+func runThemeConfig(cmd *cobra.Command, args []string) {
+	themeName, action, key := args[0], args[1], args[2]
+
+	keyPath := utils.SplitConfigKey(key)
+	if len(keyPath) == 0 {
+		utils.PrintError("Config key must not be empty")
+		exit(1)
+	}
+
+	switch action {
+	case "get":
+		if len(args) != 3 {
+			utils.PrintError("Usage: wordma theme config <name> get <dotted.key>")
+			exit(1)
+		}
+	case "set":
+		if len(args) != 4 {
+			utils.PrintError("Usage: wordma theme config <name> set <dotted.key> <value>")
+			exit(1)
+		}
+	default:
+		utils.PrintError(fmt.Sprintf("Unknown action '%s', expected 'get' or 'set'", action))
+		exit(1)
+	}
+
+	projectRoot, themePath := requireThemePath(themeName)
+	if action == "set" {
+		lock := lockProject(projectRoot, cmd)
+		defer lock.Release()
+
+		// 修改前恢复覆盖层合并过的配置文件，否则 dev 或 build 结束时恢复备份会丢掉这次修改
+		overlayLock, err := lockThemeOverlay(projectRoot, themeName, cmd.CommandPath(), color.Output)
+		if err != nil {
+			utils.PrintError(err.Error())
+			exit(1)
+		}
+		defer overlayLock.Release()
+
+		if err := restoreThemeOverlay(projectRoot, themeName, themePath); err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to restore overridden theme files: %v", err))
+			exit(1)
+		}
+	}
+
+	entries, err := loadConfigEntries(projectRoot, themePath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to read theme config: %v", err))
+		exit(1)
+	}
+	if len(entries) == 0 {
+		utils.PrintError(fmt.Sprintf("No configuration files found in '%s'", filepath.Join(themePath, "config")))
+		exit(1)
+	}
+
+	if action == "get" {
+		runThemeConfigGet(entries, key, keyPath)
+		return
+	}
+	runThemeConfigSet(projectRoot, themeName, themePath, entries, key, keyPath, utils.ParseConfigValueArg(args[3]))
+}
+
+// runThemeConfigGet 输出配置项的值：标量直接输出，对象和数组输出为 JSON
+func runThemeConfigGet(entries []*themeConfigEntry, key string, keyPath []string) {
+	for _, entry := range entries {
+		value, ok := utils.LookupConfigValue(entry.Value, keyPath)
+		if !ok {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			fmt.Println(v)
+		case nil:
+			fmt.Println("null")
+		default:
+			encoded, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				utils.PrintError(fmt.Sprintf("Failed to format value: %v", err))
+				exit(1)
+			}
+			fmt.Println(string(encoded))
+		}
+		return
+	}
+
+	utils.PrintError(fmt.Sprintf("Key '%s' not found in theme config", key))
+	exit(1)
+}
+
+// runThemeConfigSet 修改配置项并在写入前按主题提供的 schema 校验
+func runThemeConfigSet(projectRoot, themeName, themePath string, entries []*themeConfigEntry, key string, keyPath []string, value interface{}) {
+	target, err := selectThemeConfigEntry(entries, keyPath, themeConfigFile)
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+
+	updated, err := utils.SetConfigValue(target.Path, target.Data, keyPath, value)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to set '%s' in %s: %v", key, target.Path, err))
+		exit(1)
+	}
+
+	// 确认修改后的文件仍然可以解析
+	updatedValue, err := utils.ParseConfigData(target.Path, updated)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Refusing to write %s: %v", target.Path, err))
+		exit(1)
+	}
+
+	if !themeConfigNoValidate {
+		schemaPath, err := findThemeConfigSchema(themePath)
+		if err != nil {
+			utils.PrintError(err.Error())
+			exit(1)
+		}
+		if schemaPath != "" {
+			schema, err := utils.LoadSchema(schemaPath)
+			if err != nil {
+				utils.PrintError(err.Error())
+				exit(1)
+			}
+
+			// 校验替换该文件后的有效配置（包含覆盖层）
+			overrides, err := loadConfigEntries(projectRoot, getOverridesDir(projectRoot, themeName))
+			if err != nil {
+				utils.PrintError(fmt.Sprintf("Failed to read overrides: %v", err))
+				exit(1)
+			}
+			updatedEntry := *target
+			updatedEntry.Data, updatedEntry.Value = updated, updatedValue
+			var effective []*themeConfigEntry
+			for _, entry := range entries {
+				if entry == target {
+					entry = &updatedEntry
+				}
+				effective = append(effective, entry)
+			}
+			effective = append(effective, overrides...)
+
+			issues := validateConfigEntries(schema, effective)
+			if len(issues) > 0 {
+				utils.PrintError(fmt.Sprintf("New value does not match the theme's config schema (%s):", filepath.Base(schemaPath)))
+				printConfigIssues(issues)
+				utils.PrintInfo("Use --no-validate to write the value anyway")
+				exit(1)
+			}
+		}
+	}
+
+	fullPath := filepath.Join(themePath, target.Path)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to stat %s: %v", target.Path, err))
+		exit(1)
+	}
+	err = os.WriteFile(fullPath, updated, info.Mode())
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to write %s: %v", target.Path, err))
+		exit(1)
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Set '%s' in %s of theme '%s'", key, filepath.ToSlash(target.Path), themeName))
+}
+
+// selectThemeConfigEntry 选择要修改的配置文件：已包含该键的文件优先，其次是 --file 指定的文件，
+// 再次是包含最长父路径的文件
+func selectThemeConfigEntry(entries []*themeConfigEntry, keyPath []string, file string) (*themeConfigEntry, error) {
+	for _, entry := range entries {
+		if _, ok := utils.LookupConfigValue(entry.Value, keyPath); ok {
+			return entry, nil
+		}
+	}
+
+	if file != "" {
+		want := filepath.ToSlash(filepath.Join("config", file))
+		for _, entry := range entries {
+			if filepath.ToSlash(entry.Path) == want {
+				return entry, nil
+			}
+		}
+		return nil, fmt.Errorf("config file '%s' not found in theme config directory", file)
+	}
+
+	var best *themeConfigEntry
+	bestDepth := 0
+	for _, entry := range entries {
+		for depth := len(keyPath) - 1; depth > bestDepth; depth-- {
+			if parent, ok := utils.LookupConfigValue(entry.Value, keyPath[:depth]); ok {
+				if _, isMap := parent.(map[string]interface{}); isMap {
+					best, bestDepth = entry, depth
+				}
+				break
+			}
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	if len(entries) == 1 {
+		return entries[0], nil
+	}
+	return nil, fmt.Errorf("key '%s' not found in theme config, use --file to choose the file to add it to", strings.Join(keyPath, "."))
+}
+
+// loadConfigEntries 读取 baseDir/config 目录下的全部 JSON/YAML 文件（按路径排序），
+// baseDir 可以是主题目录或覆盖层目录
+func loadConfigEntries(projectRoot, baseDir string) ([]*themeConfigEntry, error) {
+	configDir := filepath.Join(baseDir, "config")
+	if !utils.FileExists(configDir) {
+		return nil, nil
+	}
+
+	var entries []*themeConfigEntry
+	err := filepath.Walk(configDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !utils.IsConfigFile(path) || strings.HasSuffix(path, ".schema.json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		value, err := utils.ParseConfigData(path, data)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		source, err := filepath.Rel(projectRoot, path)
+		if err != nil {
+			source = path
+		}
+		entries = append(entries, &themeConfigEntry{
+			Path:   relPath,
+			Source: filepath.ToSlash(source),
+			Data:   data,
+			Value:  value,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// mergeThemeConfig 按文件顺序深度合并全部配置，得到主题看到的完整配置
+func mergeThemeConfig(entries []*themeConfigEntry) interface{} {
+	var merged interface{} = map[string]interface{}{}
+	for _, entry := range entries {
+		merged = utils.DeepMerge(merged, entry.Value)
+	}
+	return merged
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"wordma-cli/utils"
+)
+
+var themeValidateCmd = &cobra.Command{
+	Use:   "validate <name>",
+	Short: "Validate a theme's configuration against its schema",
+	Long: `Check the effective configuration of a theme (theme config files merged with
+overrides/<theme>/config) against the schema the theme ships, either config.schema.json
+or the file declared as "wordma.configSchema" in its package.json.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runThemeValidate,
+}
+
+func init() {
+	themeCmd.AddCommand(themeValidateCmd)
+}
+
+// configIssue 是一条定位到文件和行的配置校验错误
+type configIssue struct {
+	File    string
+	Line    int
+	Key     string
+	Message string
+}
+
+func runThemeValidate(cmd *cobra.Command, args []string) {
+	themeName := args[0]
+	projectRoot, themePath := requireThemePath(themeName)
+
+	issues, schemaPath, err := validateThemeConfig(projectRoot, themeName, themePath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to validate theme config: %v", err))
+		exit(1)
+	}
+	if schemaPath == "" {
+		utils.PrintWarning(fmt.Sprintf("Theme '%s' does not provide a config schema, nothing to validate", themeName))
+		return
+	}
+
+	if len(issues) > 0 {
+		utils.PrintError(fmt.Sprintf("Theme '%s' config has %d problem(s):", themeName, len(issues)))
+		printConfigIssues(issues)
+		exit(1)
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Theme '%s' config matches %s", themeName, filepath.Base(schemaPath)))
+}
+
+// checkThemeConfig 在 dev/build 前校验主题配置，有错误时打印并退出
+func checkThemeConfig(projectRoot, themeName, themePath string) {
+	issues, schemaPath, err := validateThemeConfig(projectRoot, themeName, themePath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to validate theme config: %v", err))
+		exit(1)
+	}
+	if schemaPath == "" {
+		return
+	}
+
+	if len(issues) > 0 {
+		utils.PrintError(fmt.Sprintf("Theme '%s' config does not match %s:", themeName, filepath.Base(schemaPath)))
+		printConfigIssues(issues)
+		utils.PrintInfo("Fix the config above or use --skip-validate to run anyway")
+		exit(1)
+	}
+	utils.PrintInfo(fmt.Sprintf("Theme '%s' config validated against schema", themeName))
+}
+
+// validateThemeConfig 校验主题的有效配置（主题配置 + 覆盖层），主题没有 schema 时 schemaPath 为空
+func validateThemeConfig(projectRoot, themeName, themePath string) ([]configIssue, string, error) {
+	schemaPath, err := findThemeConfigSchema(themePath)
+	if err != nil || schemaPath == "" {
+		return nil, "", err
+	}
+
+	schema, err := utils.LoadSchema(schemaPath)
+	if err != nil {
+		return nil, schemaPath, err
+	}
+
+	entries, err := loadConfigEntries(projectRoot, themePath)
+	if err != nil {
+		return nil, schemaPath, err
+	}
+	overrides, err := loadConfigEntries(projectRoot, getOverridesDir(projectRoot, themeName))
+	if err != nil {
+		return nil, schemaPath, err
+	}
+
+	return validateConfigEntries(schema, append(entries, overrides...)), schemaPath, nil
+}
+
+// validateConfigEntries 校验合并后的配置，并把每个错误定位到最终生效的文件和行
+func validateConfigEntries(schema *utils.Schema, entries []*themeConfigEntry) []configIssue {
+	var issues []configIssue
+	for _, schemaErr := range schema.Validate(mergeThemeConfig(entries)) {
+		issue := configIssue{Key: schemaErr.Key(), Message: schemaErr.Message}
+
+		// 后面的文件覆盖前面的文件，因此倒序查找
+		bestDepth := -1
+		for i := len(entries) - 1; i >= 0; i-- {
+			line, depth := utils.LocateConfigKey(entries[i].Path, entries[i].Data, schemaErr.Path)
+			if line > 0 && depth > bestDepth {
+				issue.File, issue.Line, bestDepth = entries[i].Source, line, depth
+			}
+			if depth == len(schemaErr.Path) {
+				break
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// printConfigIssues 以 file:line: key: message 的格式输出校验错误
+func printConfigIssues(issues []configIssue) {
+	for _, issue := range issues {
+		if issue.File == "" {
+			fmt.Printf("  - %s: %s\n", issue.Key, issue.Message)
+			continue
+		}
+		fmt.Printf("  - %s:%d: %s: %s\n", issue.File, issue.Line, issue.Key, issue.Message)
+	}
+}
+
+// findThemeConfigSchema 查找主题提供的配置 schema：优先使用 package.json 中
+// "wordma.configSchema" 声明的文件，其次是 config.schema.json；不存在时返回空字符串
+func findThemeConfigSchema(themePath string) (string, error) {
+	manifest, err := loadThemeManifest(themePath)
+	if err != nil && !os.IsNotExist(err) {
+		// package.json 无法解析时不能确定是否声明了 schema，不回退到 config.schema.json
+		return "", fmt.Errorf("failed to read package.json: %v", err)
+	}
+	if err == nil && manifest.ConfigSchema != "" {
+		path := filepath.Join(themePath, filepath.FromSlash(manifest.ConfigSchema))
+		if !utils.FileExists(path) {
+			return "", fmt.Errorf("config schema '%s' declared in package.json does not exist", manifest.ConfigSchema)
+		}
+		return path, nil
+	}
+
+	for _, candidate := range []string{"config.schema.json", filepath.Join("config", "config.schema.json")} {
+		path := filepath.Join(themePath, candidate)
+		if utils.FileExists(path) {
+			return path, nil
+		}
+	}
+	return "", nil
+}
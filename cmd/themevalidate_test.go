@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindThemeConfigSchema(t *testing.T) {
+	themePath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(themePath, "config.schema.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 package.json 时使用 config.schema.json
+	path, err := findThemeConfigSchema(themePath)
+	if err != nil || path != filepath.Join(themePath, "config.schema.json") {
+		t.Errorf("expected config.schema.json, got %q (%v)", path, err)
+	}
+
+	// package.json 无法解析时报错，而不是忽略其中可能声明的 schema
+	if err := os.WriteFile(filepath.Join(themePath, "package.json"), []byte(`{"wordma": {"configSchema": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findThemeConfigSchema(themePath); err == nil {
+		t.Error("expected an error for a malformed package.json")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"wordma-cli/utils"
 )
@@ -36,7 +37,10 @@ var updateThemeCmd = &cobra.Command{
 	Run:   runUpdateTheme,
 }
 
+var updateSkipCompat bool
+
 func init() {
+	updateThemeCmd.Flags().BoolVar(&updateSkipCompat, "skip-compat", false, "Update even if the new theme version is not compatible with this project")
 	updateThemesCmd.AddCommand(updateThemeCmd)
 	updateCmd.AddCommand(updateThemesCmd)
 }
@@ -49,7 +53,7 @@ func runSelfUpdate(cmd *cobra.Command, args []string) {
 	versionInfo, err := getVersionInfo()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to check for updates: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Display current version info
@@ -97,7 +101,7 @@ func runSelfUpdate(cmd *cobra.Command, args []string) {
 	// Perform update
 	if err := performSelfUpdate(versionInfo.Latest); err != nil {
 		utils.PrintError(fmt.Sprintf("Update failed: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	fmt.Printf("\n%s Successfully updated to version %s!\n", 
@@ -266,23 +270,26 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 	if !utils.CheckCommand("git") {
 		utils.PrintError("Git is required for updating themes")
 		fmt.Printf("  %s\n", utils.GetInstallInstructions("git"))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取项目根目录
 	projectRoot, err := utils.GetProjectRoot()
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to find project root: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
+	lock := lockProject(projectRoot, cmd)
+	defer lock.Release()
+
 	// 检查主题目录是否存在
 	themePath := filepath.Join(projectRoot, "themes", themeName)
 	if !utils.FileExists(themePath) {
 		utils.PrintError(fmt.Sprintf("Theme '%s' not found in themes directory", themeName))
 		utils.PrintInfo("Available themes:")
 		listAvailableThemes(projectRoot)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查主题目录是否是git仓库
@@ -291,16 +298,31 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 		utils.PrintError(fmt.Sprintf("Theme '%s' is not a git repository", themeName))
 		utils.PrintInfo("This theme cannot be updated automatically")
 		utils.PrintInfo("You may need to manually update it or re-add it using 'wordma add theme <git-url>'")
-		os.Exit(1)
+		exit(1)
 	}
 
 	utils.PrintInfo(fmt.Sprintf("Updating theme '%s'...", themeName))
 
+	// 恢复异常退出时残留的覆盖层文件，避免它们被当作本地更改；
+	// 持有覆盖层锁，避免恢复正在运行的 dev 或 build 应用的覆盖层
+	overlayLock, err := lockThemeOverlay(projectRoot, themeName, cmd.CommandPath(), color.Output)
+	if err != nil {
+		utils.PrintError(err.Error())
+		exit(1)
+	}
+	defer overlayLock.Release()
+
+	err = restoreThemeOverlay(projectRoot, themeName, themePath)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to restore overridden theme files: %v", err))
+		exit(1)
+	}
+
 	// 备份配置文件
 	configBackupPath, hasConfig, err := backupConfigIfExists(themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to backup config: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 	if hasConfig {
 		utils.PrintInfo("Configuration files backed up")
@@ -310,7 +332,7 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 	err = utils.RunCommandInDir(themePath, "git", "status", "--porcelain")
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to check git status: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取当前分支
@@ -318,35 +340,60 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 	err = utils.RunCommandInDir(themePath, "git", "fetch", "origin")
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to fetch from remote: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 获取当前分支名
 	currentBranch, err := getCurrentBranch(themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to get current branch: %v", err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 更新前检查远程版本的兼容性
+	remotePackageJSON, err := getRemoteFile(themePath, currentBranch, "package.json")
+	if err == nil {
+		manifest, err := parseThemeManifest(remotePackageJSON)
+		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Failed to read theme manifest of the new version: %v", err))
+		} else {
+			if manifest.Version != "" {
+				utils.PrintInfo(fmt.Sprintf("New version: %s", manifest.Version))
+			}
+			if !enforceThemeCompatibility(projectRoot, manifest, updateSkipCompat) {
+				utils.PrintError(fmt.Sprintf("The new version of theme '%s' is not compatible with this project", themeName))
+				cleanupBackup(configBackupPath)
+				exit(1)
+			}
+		}
 	}
 
 	// 检查是否有本地更改
 	hasLocalChanges, err := hasUncommittedChanges(themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to check for local changes: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查是否有配置文件更改
 	hasConfigChanges, err := hasConfigFileChanges(themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to check config changes: %v", err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 检查是否有非配置文件的更改
 	hasNonConfigChanges, err := hasNonConfigFileChanges(themePath)
 	if err != nil {
 		utils.PrintError(fmt.Sprintf("Failed to check non-config changes: %v", err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 记录更新前的状态，供 wordma theme rollback 使用
+	journalEntry, err := newUpdateJournalEntry(projectRoot, themeName, themePath, currentBranch)
+	if err != nil {
+		utils.PrintError(fmt.Sprintf("Failed to record update journal: %v", err))
+		exit(1)
 	}
 
 	if hasLocalChanges {
@@ -357,8 +404,10 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 			err = stashNonConfigChanges(themePath)
 			if err != nil {
 				utils.PrintError(fmt.Sprintf("Failed to stash non-config changes: %v", err))
-				os.Exit(1)
+				discardUpdateJournalEntry(journalEntry)
+				exit(1)
 			}
+			journalEntry = recordJournalStash(themePath, journalEntry)
 			utils.PrintInfo("Non-config changes stashed successfully")
 		} else if hasNonConfigChanges {
 			// 只有非配置文件更改
@@ -367,8 +416,10 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 			err = utils.RunCommandInDir(themePath, "git", "stash", "push", "-m", "wordma-cli auto stash before update")
 			if err != nil {
 				utils.PrintError(fmt.Sprintf("Failed to stash changes: %v", err))
-				os.Exit(1)
+				discardUpdateJournalEntry(journalEntry)
+				exit(1)
 			}
+			journalEntry = recordJournalStash(themePath, journalEntry)
 			utils.PrintInfo("Local changes stashed successfully")
 		} else if hasConfigChanges {
 			// 只有配置文件更改
@@ -390,7 +441,18 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 				utils.PrintWarning("Failed to restore stashed changes. You may need to manually run 'git stash pop' in the theme directory")
 			}
 		}
-		os.Exit(1)
+		discardUpdateJournalEntry(journalEntry)
+		exit(1)
+	}
+
+	// 写入更新日志
+	if journalEntry != nil {
+		err = appendUpdateJournalEntry(projectRoot, themeName, journalEntry)
+		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Failed to write update journal: %v", err))
+		} else {
+			utils.PrintInfo(fmt.Sprintf("Update recorded, run 'wordma theme rollback %s' to undo it", themeName))
+		}
 	}
 
 	// 处理配置文件恢复
@@ -417,6 +479,19 @@ func runUpdateTheme(cmd *cobra.Command, args []string) {
 	fmt.Printf("  2. wordma dev %s (to test the updated theme)\n", themeName)
 }
 
+// recordJournalStash 在更新记录中保存刚创建的 stash 的哈希。无法获取时放弃这条记录并返回 nil：
+// 没有 stash 的记录回滚时会丢失这些更改，更新照常进行但不能用 theme rollback 撤销
+func recordJournalStash(themePath string, entry *UpdateJournalEntry) *UpdateJournalEntry {
+	stashRef, err := getLatestStashRef(themePath)
+	if err != nil {
+		utils.PrintWarning(fmt.Sprintf("Failed to read the stash just created, this update will not be recorded for rollback: %v", err))
+		discardUpdateJournalEntry(entry)
+		return nil
+	}
+	entry.StashRef = stashRef
+	return entry
+}
+
 // getCurrentBranch 获取当前git分支名
 func getCurrentBranch(repoPath string) (string, error) {
 	cmd := utils.NewCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -428,6 +503,13 @@ func getCurrentBranch(repoPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// getRemoteFile 读取远程分支上的文件内容
+func getRemoteFile(repoPath, branch, file string) ([]byte, error) {
+	cmd := utils.NewCommand("git", "show", fmt.Sprintf("origin/%s:%s", branch, file))
+	cmd.Dir = repoPath
+	return cmd.Output()
+}
+
 // hasUncommittedChanges 检查是否有未提交的更改
 func hasUncommittedChanges(repoPath string) (bool, error) {
 	cmd := utils.NewCommand("git", "status", "--porcelain")
@@ -0,0 +1,330 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// assetHashLength 是文件名中内容哈希的长度
+const assetHashLength = 8
+
+var (
+	// hashedNamePattern 匹配已经带有内容哈希的文件名（如 app.3f2a9c1d.js、app-3f2a9c1d.js）
+	hashedNamePattern = regexp.MustCompile(`[.-]([0-9a-f]{8,})\.[^.]+$`)
+	// jsStringPattern 匹配 JS 中的字符串字面量
+	jsStringPattern = regexp.MustCompile("\"(?:[^\"\\\\\\n]|\\\\.)*\"|'(?:[^'\\\\\\n]|\\\\.)*'|`(?:[^`\\\\]|\\\\.)*`")
+)
+
+// AssetHashOptions 是资源文件名哈希化的设置
+type AssetHashOptions struct {
+	// Extensions 需要哈希化的文件扩展名（如 ".css"）
+	Extensions []string
+	// Exclude 不哈希化的文件，glob 模式，规则同 MatchGlob
+	Exclude []string
+	// BasePath 站点的基础路径，根相对 URL 以它开头
+	BasePath string
+	// SiteHost 站点的主机名，指向它的绝对 URL 同样会被重写
+	SiteHost string
+}
+
+// AssetHashResult 是一次哈希化的结果
+type AssetHashResult struct {
+	// Manifest 原文件路径到哈希化后路径的映射，均相对于输出目录
+	Manifest map[string]string
+	// Rewritten 修改了引用的文件数
+	Rewritten int
+	// Cyclic 互相引用、无法确定哈希而保留原名的 CSS/JS 文件
+	Cyclic []string
+}
+
+// assetHasher 保存哈希化过程中的状态
+type assetHasher struct {
+	dir     string
+	opts    AssetHashOptions
+	renamed map[string]string // 原路径 -> 新路径
+	result  *AssetHashResult
+}
+
+// HashAssets 将 dir 中的静态资源重命名为带内容哈希的文件名，并重写 HTML、CSS 和 JS 中对它们的引用。
+// 被引用的文件先于引用它的文件处理，因此 CSS/JS 的哈希包含重写后的引用；已经带有哈希的文件名保持不变
+func HashAssets(dir string, opts AssetHashOptions) (*AssetHashResult, error) {
+	if opts.BasePath == "" {
+		opts.BasePath = "/"
+	}
+	h := &assetHasher{
+		dir:     dir,
+		opts:    opts,
+		renamed: make(map[string]string),
+		result:  &AssetHashResult{Manifest: make(map[string]string)},
+	}
+
+	extensions := make(map[string]bool)
+	for _, ext := range opts.Extensions {
+		extensions[strings.ToLower(ext)] = true
+	}
+
+	var files, candidates []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		files = append(files, relPath)
+		if extensions[strings.ToLower(path.Ext(relPath))] && !h.isExcluded(relPath) {
+			candidates = append(candidates, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 不引用其他文件的资源（图片、字体等）直接哈希化
+	pending := make(map[string]bool)
+	for _, relPath := range candidates {
+		if isTextAsset(relPath) {
+			pending[relPath] = true
+		} else if err := h.hashFile(relPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// CSS/JS 按依赖顺序处理：只引用已处理文件的先处理
+	for len(pending) > 0 {
+		var ready []string
+		for _, relPath := range sortedKeys(pending) {
+			refs, err := h.references(relPath)
+			if err != nil {
+				return nil, err
+			}
+			blocked := false
+			for _, ref := range refs {
+				if ref != relPath && pending[ref] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, relPath)
+			}
+		}
+
+		if len(ready) == 0 {
+			// 剩下的文件互相引用，保留原名
+			h.result.Cyclic = sortedKeys(pending)
+			break
+		}
+		for _, relPath := range ready {
+			delete(pending, relPath)
+			changed, err := h.rewriteFile(relPath)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				h.result.Rewritten++
+			}
+			if err := h.hashFile(relPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 最后重写其余文件（HTML 和保留原名的 CSS/JS）中的引用
+	for _, relPath := range files {
+		if _, ok := h.renamed[relPath]; ok {
+			continue
+		}
+		if !isTextAsset(relPath) && !isHTMLFile(relPath) {
+			continue
+		}
+		changed, err := h.rewriteFile(relPath)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			h.result.Rewritten++
+		}
+	}
+
+	return h.result, nil
+}
+
+// isExcluded 判断文件是否被排除或已经带有哈希
+func (h *assetHasher) isExcluded(relPath string) bool {
+	// 全是字母的部分（如 app-settings.js）不是哈希
+	if match := hashedNamePattern.FindStringSubmatch(path.Base(relPath)); match != nil && strings.ContainsAny(match[1], "0123456789") {
+		return true
+	}
+	for _, pattern := range h.opts.Exclude {
+		if MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextAsset 判断文件是否是可能引用其他资源的 CSS/JS
+func isTextAsset(relPath string) bool {
+	switch strings.ToLower(path.Ext(relPath)) {
+	case ".css", ".js", ".mjs":
+		return true
+	}
+	return false
+}
+
+// isHTMLFile 判断文件是否是 HTML
+func isHTMLFile(relPath string) bool {
+	switch strings.ToLower(path.Ext(relPath)) {
+	case ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// hashFile 按文件内容重命名文件，并记录到 manifest
+func (h *assetHasher) hashFile(relPath string) error {
+	src := filepath.Join(h.dir, filepath.FromSlash(relPath))
+	hash, err := HashFile(src)
+	if err != nil {
+		return err
+	}
+
+	ext := path.Ext(relPath)
+	hashedPath := strings.TrimSuffix(relPath, ext) + "." + hash[:assetHashLength] + ext
+	if err := os.Rename(src, filepath.Join(h.dir, filepath.FromSlash(hashedPath))); err != nil {
+		return fmt.Errorf("failed to rename %s: %v", relPath, err)
+	}
+	h.renamed[relPath] = hashedPath
+	h.result.Manifest[relPath] = hashedPath
+	return nil
+}
+
+// rewriteFile 重写文件中指向已哈希化资源的引用，返回文件是否被修改
+func (h *assetHasher) rewriteFile(relPath string) (bool, error) {
+	file := filepath.Join(h.dir, filepath.FromSlash(relPath))
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	result := h.rewrite(relPath, data, func(ref, target string) (string, bool) {
+		hashedPath, ok := h.renamed[target]
+		if !ok {
+			return "", false
+		}
+		return replaceURLBase(ref, path.Base(hashedPath)), true
+	})
+	if string(result) == string(data) {
+		return false, nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(file, result, info.Mode().Perm())
+}
+
+// references 返回文件引用的输出目录中的文件
+func (h *assetHasher) references(relPath string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(h.dir, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	h.rewrite(relPath, data, func(ref, target string) (string, bool) {
+		refs = append(refs, target)
+		return "", false
+	})
+	return refs, nil
+}
+
+// rewrite 找出文件内容中的引用并交给 fn 处理；fn 的参数是引用原文和它指向的文件
+func (h *assetHasher) rewrite(relPath string, data []byte, fn func(ref, target string) (string, bool)) []byte {
+	fromDir := path.Dir(relPath)
+	rewriteURL := func(ref string) (string, bool) {
+		if target, ok := h.resolve(ref, fromDir); ok {
+			return fn(ref, target)
+		}
+		return "", false
+	}
+
+	switch {
+	case isHTMLFile(relPath):
+		return RewriteHTMLURLs(data, rewriteURL)
+	case strings.EqualFold(path.Ext(relPath), ".css"):
+		return RewriteCSSURLs(data, rewriteURL)
+	}
+
+	// JS 中的字符串：相对路径先按 JS 文件所在目录解析（import 和 new URL(..., import.meta.url)），
+	// 再按站点根目录解析（页面中使用的路径）
+	return jsStringPattern.ReplaceAllFunc(data, func(literal []byte) []byte {
+		quote, value := literal[0], string(literal[1:len(literal)-1])
+		if value == "" || strings.Contains(value, "${") || strings.ContainsAny(value, "\\ \n") {
+			return literal
+		}
+		for _, dir := range []string{fromDir, "."} {
+			target, ok := h.resolve(value, dir)
+			if !ok || !h.exists(target) {
+				continue
+			}
+			if newValue, ok := fn(value, target); ok {
+				return []byte(string(quote) + newValue + string(quote))
+			}
+			return literal
+		}
+		return literal
+	})
+}
+
+// exists 判断输出目录中是否有该文件（包括已被重命名的原文件）
+func (h *assetHasher) exists(target string) bool {
+	if _, ok := h.renamed[target]; ok {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(h.dir, filepath.FromSlash(target)))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// replaceURLBase 将 URL 路径的最后一段替换为 base，保留查询参数和锚点
+func replaceURLBase(ref, base string) string {
+	end := len(ref)
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		end = i
+	}
+	start := strings.LastIndex(ref[:end], "/") + 1
+	return ref[:start] + (&url.URL{Path: base}).EscapedPath() + ref[end:]
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolve 将引用解析为输出目录中的文件路径，不指向站内文件时返回 false
+func (h *assetHasher) resolve(ref, fromDir string) (string, bool) {
+	target, _, ok := SiteResolver{BasePath: h.opts.BasePath, SiteHost: h.opts.SiteHost}.Resolve(ref, fromDir)
+	if !ok || target == "" || strings.HasSuffix(target, "/") {
+		return "", false
+	}
+	return target, true
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHashAssets(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":            `<link href="/blog/css/main.css" rel="stylesheet"><img src="img/logo.png?v=1"><script src="/blog/js/app.js"></script><link rel="icon" href="/blog/favicon.ico">`,
+		"posts/a/index.html":    `<img src="../../img/logo.png"><a href="https://example.com/blog/img/logo.png#x">logo</a>`,
+		"css/main.css":          `body { background: url(../img/logo.png); } @import "/blog/css/theme.css";`,
+		"css/theme.css":         `h1 { color: red; }`,
+		"js/app.js":             `import "./util.js"; const logo = "/blog/img/logo.png"; const text = "hello.png";`,
+		"js/util.js":            `export const x = 1;`,
+		"js/vendor.3f2a9c1d.js": `const x = "img/logo.png";`,
+		"img/logo.png":          "png",
+		"favicon.ico":           "ico",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := HashAssets(dir, AssetHashOptions{
+		Extensions: []string{".css", ".js", ".png", ".ico"},
+		Exclude:    []string{"favicon.ico"},
+		BasePath:   "/blog/",
+		SiteHost:   "example.com",
+	})
+	if err != nil {
+		t.Fatalf("HashAssets() error = %v", err)
+	}
+
+	hashed := regexp.MustCompile(`^(.+)\.[0-9a-f]{8}(\.[a-z]+)$`)
+	for _, original := range []string{"css/main.css", "css/theme.css", "js/app.js", "js/util.js", "img/logo.png"} {
+		newPath, ok := result.Manifest[original]
+		if !ok {
+			t.Errorf("%s is missing from the manifest", original)
+			continue
+		}
+		if m := hashed.FindStringSubmatch(newPath); m == nil || m[1]+m[2] != original {
+			t.Errorf("manifest[%s] = %s, want a hashed name", original, newPath)
+		}
+		if !FileExists(filepath.Join(dir, newPath)) || FileExists(filepath.Join(dir, original)) {
+			t.Errorf("%s was not renamed to %s", original, newPath)
+		}
+	}
+	for _, kept := range []string{"favicon.ico", "js/vendor.3f2a9c1d.js"} {
+		if _, ok := result.Manifest[kept]; ok {
+			t.Errorf("%s should keep its name", kept)
+		}
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	logo := filepath.Base(result.Manifest["img/logo.png"])
+
+	checks := map[string][]string{
+		"index.html": {
+			`href="/blog/` + result.Manifest["css/main.css"] + `"`,
+			`src="img/` + logo + `?v=1"`,
+			`src="/blog/` + result.Manifest["js/app.js"] + `"`,
+			`href="/blog/favicon.ico"`,
+		},
+		"posts/a/index.html":            {`src="../../img/` + logo + `"`, `href="https://example.com/blog/img/` + logo + `#x"`},
+		result.Manifest["css/main.css"]: {`url(../img/` + logo + `)`, `@import "/blog/` + result.Manifest["css/theme.css"] + `"`},
+		result.Manifest["js/app.js"]:    {`import "./` + filepath.Base(result.Manifest["js/util.js"]) + `"`, `"/blog/img/` + logo + `"`, `"hello.png"`},
+		"js/vendor.3f2a9c1d.js":         {`"img/` + logo + `"`},
+	}
+	for name, wants := range checks {
+		content := read(name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s = %s, want it to contain %s", name, content, want)
+			}
+		}
+	}
+}
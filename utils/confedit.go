@@ -0,0 +1,735 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitConfigKey 将点分隔的键（如 nav.items.0.title）拆分为路径
+func SplitConfigKey(key string) []string {
+	var parts []string
+	for _, part := range strings.Split(key, ".") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// LookupConfigValue 按路径在解析后的配置中查找值，数组使用数字下标
+func LookupConfigValue(doc interface{}, keyPath []string) (interface{}, bool) {
+	current := doc
+	for _, key := range keyPath {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// ParseConfigValueArg 解析命令行传入的值：合法的 JSON 字面量按 JSON 解析，否则视为字符串；
+// 整数解析为 int64，其余数字解析为 float64
+func ParseConfigValueArg(arg string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(arg))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return arg
+	}
+	return convertJSONNumbers(value)
+}
+
+// convertJSONNumbers 将 json.Number 转换为 int64 或 float64
+func convertJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertJSONNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertJSONNumbers(item)
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// SetConfigValue 在不破坏原有格式的前提下修改配置文件内容中的指定键，键不存在时插入
+func SetConfigValue(path string, data []byte, keyPath []string, value interface{}) ([]byte, error) {
+	if len(keyPath) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	if isYAMLFile(path) {
+		return setYAMLValue(data, keyPath, value)
+	}
+	return setJSONValue(data, keyPath, value)
+}
+
+// nestValue 将剩余路径包装为嵌套对象，用于插入不存在的键
+func nestValue(keyPath []string, value interface{}) interface{} {
+	for i := len(keyPath) - 1; i >= 0; i-- {
+		value = map[string]interface{}{keyPath[i]: value}
+	}
+	return value
+}
+
+// ---------- JSON ----------
+
+// jsonNode 是带有字节偏移的 JSON 语法树节点
+type jsonNode struct {
+	kind    byte // '{'、'[' 或 'v'（标量）
+	start   int
+	end     int
+	members []jsonMember
+	items   []*jsonNode
+}
+
+// jsonMember 是 JSON 对象中的一个成员
+type jsonMember struct {
+	key      string
+	keyStart int
+	value    *jsonNode
+}
+
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+// parseJSONTree 解析 JSON 并保留每个节点在原文中的位置
+func parseJSONTree(data []byte) (*jsonNode, error) {
+	s := &jsonScanner{data: data}
+	node, err := s.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	if s.pos < len(s.data) {
+		return nil, s.errorf("unexpected trailing content")
+	}
+	return node, nil
+}
+
+func (s *jsonScanner) errorf(format string, args ...interface{}) error {
+	line := bytes.Count(s.data[:s.pos], []byte("\n")) + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) parseValue() (*jsonNode, error) {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return nil, s.errorf("unexpected end of input")
+	}
+
+	switch s.data[s.pos] {
+	case '{':
+		return s.parseObject()
+	case '[':
+		return s.parseArray()
+	case '"':
+		start := s.pos
+		if _, err := s.parseString(); err != nil {
+			return nil, err
+		}
+		return &jsonNode{kind: 'v', start: start, end: s.pos}, nil
+	default:
+		start := s.pos
+		for s.pos < len(s.data) && !strings.ContainsRune(",}] \t\r\n", rune(s.data[s.pos])) {
+			s.pos++
+		}
+		if start == s.pos {
+			return nil, s.errorf("unexpected character %q", s.data[s.pos])
+		}
+		return &jsonNode{kind: 'v', start: start, end: s.pos}, nil
+	}
+}
+
+func (s *jsonScanner) parseString() (string, error) {
+	start := s.pos
+	s.pos++
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			var value string
+			if err := json.Unmarshal(s.data[start:s.pos], &value); err != nil {
+				return "", s.errorf("invalid string: %v", err)
+			}
+			return value, nil
+		default:
+			s.pos++
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+func (s *jsonScanner) parseObject() (*jsonNode, error) {
+	node := &jsonNode{kind: '{', start: s.pos}
+	s.pos++
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return nil, s.errorf("unterminated object")
+		}
+		if s.data[s.pos] == '}' {
+			s.pos++
+			node.end = s.pos
+			return node, nil
+		}
+		if len(node.members) > 0 {
+			if s.data[s.pos] != ',' {
+				return nil, s.errorf("expected ',' in object")
+			}
+			s.pos++
+			s.skipSpace()
+		}
+		if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+			return nil, s.errorf("expected object key")
+		}
+		keyStart := s.pos
+		key, err := s.parseString()
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+			return nil, s.errorf("expected ':' after object key")
+		}
+		s.pos++
+		value, err := s.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.members = append(node.members, jsonMember{key: key, keyStart: keyStart, value: value})
+	}
+}
+
+func (s *jsonScanner) parseArray() (*jsonNode, error) {
+	node := &jsonNode{kind: '[', start: s.pos}
+	s.pos++
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return nil, s.errorf("unterminated array")
+		}
+		if s.data[s.pos] == ']' {
+			s.pos++
+			node.end = s.pos
+			return node, nil
+		}
+		if len(node.items) > 0 {
+			if s.data[s.pos] != ',' {
+				return nil, s.errorf("expected ',' in array")
+			}
+			s.pos++
+		}
+		item, err := s.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, item)
+	}
+}
+
+// child 返回对象成员或数组元素
+func (n *jsonNode) child(key string) (*jsonNode, int) {
+	switch n.kind {
+	case '{':
+		for i, member := range n.members {
+			if member.key == key {
+				return member.value, i
+			}
+		}
+	case '[':
+		index, err := strconv.Atoi(key)
+		if err == nil && index >= 0 && index < len(n.items) {
+			return n.items[index], index
+		}
+	}
+	return nil, -1
+}
+
+// lineIndent 返回 offset 所在行的前导空白
+func lineIndent(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := lineStart
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[lineStart:end])
+}
+
+// detectJSONIndent 推测文件使用的缩进单位
+func detectJSONIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// marshalJSONAt 按所在位置的缩进序列化值
+func marshalJSONAt(value interface{}, indent, unit string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(indent, unit)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func setJSONValue(data []byte, keyPath []string, value interface{}) ([]byte, error) {
+	root, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+	unit := detectJSONIndent(data)
+
+	// 找到路径上最深的已存在节点
+	node := root
+	depth := 0
+	for depth < len(keyPath) {
+		next, _ := node.child(keyPath[depth])
+		if next == nil {
+			break
+		}
+		node = next
+		depth++
+	}
+
+	// 键已存在：替换原值
+	if depth == len(keyPath) {
+		encoded, err := marshalJSONAt(value, lineIndent(data, node.start), unit)
+		if err != nil {
+			return nil, err
+		}
+		return spliceBytes(data, node.start, node.end, encoded), nil
+	}
+
+	if node.kind != '{' {
+		return nil, fmt.Errorf("cannot add key '%s': parent is not an object", strings.Join(keyPath[:depth+1], "."))
+	}
+
+	// 键不存在：在对象末尾插入新成员
+	newValue := nestValue(keyPath[depth+1:], value)
+	key, _ := json.Marshal(keyPath[depth])
+
+	if len(node.members) == 0 {
+		indent := lineIndent(data, node.start)
+		encoded, err := marshalJSONAt(newValue, indent+unit, unit)
+		if err != nil {
+			return nil, err
+		}
+		insert := fmt.Sprintf("{\n%s%s%s: %s\n%s}", indent, unit, key, encoded, indent)
+		return spliceBytes(data, node.start, node.end, []byte(insert)), nil
+	}
+
+	last := node.members[len(node.members)-1]
+	var insert string
+	if bytes.Contains(data[node.start:last.keyStart], []byte("\n")) {
+		indent := lineIndent(data, last.keyStart)
+		encoded, err := marshalJSONAt(newValue, indent, unit)
+		if err != nil {
+			return nil, err
+		}
+		insert = fmt.Sprintf(",\n%s%s: %s", indent, key, encoded)
+	} else {
+		encoded, err := marshalJSONAt(newValue, "", "")
+		if err != nil {
+			return nil, err
+		}
+		insert = fmt.Sprintf(", %s: %s", key, encoded)
+	}
+	return spliceBytes(data, last.value.end, last.value.end, []byte(insert)), nil
+}
+
+// spliceBytes 用 replacement 替换 data[start:end]
+func spliceBytes(data []byte, start, end int, replacement []byte) []byte {
+	result := make([]byte, 0, len(data)-(end-start)+len(replacement))
+	result = append(result, data[:start]...)
+	result = append(result, replacement...)
+	return append(result, data[end:]...)
+}
+
+// ---------- YAML ----------
+
+// yamlPathNode 是路径上的一个 YAML 节点及其对应的键节点
+type yamlPathNode struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// parseYAMLTree 解析 YAML 并返回文档根节点
+func parseYAMLTree(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// yamlChild 返回映射成员或序列元素
+func yamlChild(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return nil, node.Content[index]
+		}
+	}
+	return nil, nil
+}
+
+func splitLines(data []byte) []string {
+	return strings.Split(string(data), "\n")
+}
+
+// runeColumnToByte 将 yaml.v3 的列号（从 1 开始，按字符计）转换为字节偏移
+func runeColumnToByte(line string, column int) int {
+	offset := 0
+	for i := 1; i < column && offset < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[offset:])
+		offset += size
+	}
+	return offset
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// yamlBlockEnd 返回从 keyLine（从 0 开始）开始、键位于 keyIndent 列的键值块的最后一行
+func yamlBlockEnd(lines []string, keyLine, keyIndent int) int {
+	end := keyLine
+	for i := keyLine + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := indentOf(lines[i])
+		// 与键同级的 "- item" 属于该键的序列
+		if indent > keyIndent || (indent == keyIndent && strings.HasPrefix(trimmed, "- ")) {
+			end = i
+			continue
+		}
+		break
+	}
+	return end
+}
+
+// renderYAMLScalar 将标量序列化为单行 YAML，无法单行表示时返回 false
+func renderYAMLScalar(value interface{}) (string, bool) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	}
+	encoded, err := yaml.Marshal(yamlNumbers(value))
+	if err != nil {
+		return "", false
+	}
+	rendered := strings.TrimSuffix(string(encoded), "\n")
+	if strings.Contains(rendered, "\n") {
+		return "", false
+	}
+	return rendered, true
+}
+
+// renderYAMLEntry 将 key: value 渲染为带缩进的 YAML 行
+func renderYAMLEntry(key string, value interface{}, indent int) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]interface{}{key: yamlNumbers(value)}); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+
+	prefix := strings.Repeat(" ", indent)
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		lines = append(lines, prefix+line)
+	}
+	return lines, nil
+}
+
+// yamlFloat 以不带指数的形式序列化浮点数，避免 yaml.v3 输出 1e+06 这样的写法
+type yamlFloat float64
+
+func (f yamlFloat) MarshalYAML() (interface{}, error) {
+	value := strconv.FormatFloat(float64(f), 'f', -1, 64)
+	tag := "!!float"
+	if !strings.Contains(value, ".") {
+		tag = "!!int"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}, nil
+}
+
+// yamlNumbers 返回将其中的 float64 替换为 yamlFloat 的副本
+func yamlNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = yamlNumbers(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = yamlNumbers(item)
+		}
+		return result
+	case float64:
+		return yamlFloat(v)
+	}
+	return value
+}
+
+// scalarEnd 返回单行标量在行内的结束位置，标量跨行时返回 -1。
+// flow 为 true 时标量位于 {...} 或 [...] 中，普通标量在 , } ] 处结束
+func scalarEnd(line string, start int, style yaml.Style, flow bool) int {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] == '"' {
+				return i + 1
+			}
+		}
+		return -1
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return -1
+	case style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return -1
+	}
+
+	end := len(line)
+	if comment := strings.Index(line[start:], " #"); comment >= 0 {
+		end = start + comment
+	}
+	if flow {
+		if indicator := strings.IndexAny(line[start:end], ",}]"); indicator >= 0 {
+			end = start + indicator
+		}
+	}
+	return start + len(strings.TrimRight(line[start:end], " \t"))
+}
+
+func setYAMLValue(data []byte, keyPath []string, value interface{}) ([]byte, error) {
+	root, err := parseYAMLTree(data)
+	if err != nil {
+		return nil, err
+	}
+	lines := splitLines(data)
+
+	// 找到路径上最深的已存在节点
+	path := []yamlPathNode{{value: root}}
+	for _, key := range keyPath {
+		keyNode, valueNode := yamlChild(path[len(path)-1].value, key)
+		if valueNode == nil {
+			break
+		}
+		path = append(path, yamlPathNode{key: keyNode, value: valueNode})
+	}
+	depth := len(path) - 1
+	target := path[depth]
+
+	// 键已存在
+	if depth == len(keyPath) {
+		node := target.value
+
+		// 单行标量直接在行内替换，保留行尾注释
+		if rendered, ok := renderYAMLScalar(value); ok && node.Kind == yaml.ScalarNode && node.Line > 0 {
+			flow := depth > 0 && path[depth-1].value.Style&yaml.FlowStyle != 0
+			if _, isString := value.(string); flow && isString && strings.ContainsAny(rendered, ",[]{}") {
+				// 流式集合中的普通标量不能包含这些字符
+				quoted, _ := json.Marshal(value)
+				rendered = string(quoted)
+			}
+			line := lines[node.Line-1]
+			start := runeColumnToByte(line, node.Column)
+			if end := scalarEnd(line, start, node.Style, flow); end >= start {
+				lines[node.Line-1] = line[:start] + rendered + line[end:]
+				return []byte(strings.Join(lines, "\n")), nil
+			}
+		}
+
+		if target.key == nil {
+			return nil, fmt.Errorf("cannot replace non-scalar list item '%s'", strings.Join(keyPath, "."))
+		}
+		return replaceYAMLBlock(lines, target.key, keyPath[len(keyPath)-1], value)
+	}
+
+	// 键不存在：插入到父级映射末尾
+	parent := target.value
+	newValue := nestValue(keyPath[depth+1:], value)
+	if parent.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot add key '%s': parent is not a mapping", strings.Join(keyPath[:depth+1], "."))
+	}
+
+	if len(parent.Content) == 0 {
+		if target.key == nil {
+			entry, err := renderYAMLEntry(keyPath[depth], newValue, 0)
+			if err != nil {
+				return nil, err
+			}
+			content := strings.TrimRight(string(data), "\n")
+			if strings.TrimSpace(content) == "{}" {
+				content = ""
+			}
+			if content != "" {
+				content += "\n"
+			}
+			return []byte(content + strings.Join(entry, "\n") + "\n"), nil
+		}
+		// 空映射（如 key: {}）直接替换为新内容
+		return replaceYAMLBlock(lines, target.key, keyPath[depth-1], map[string]interface{}{keyPath[depth]: newValue})
+	}
+
+	if parent.Style&yaml.FlowStyle != 0 {
+		return nil, fmt.Errorf("cannot add key '%s' to a flow-style mapping", strings.Join(keyPath[:depth+1], "."))
+	}
+
+	lastKey := parent.Content[len(parent.Content)-2]
+	entry, err := renderYAMLEntry(keyPath[depth], newValue, lastKey.Column-1)
+	if err != nil {
+		return nil, err
+	}
+
+	insertAt := yamlBlockEnd(lines, lastKey.Line-1, lastKey.Column-1) + 1
+	result := append([]string{}, lines[:insertAt]...)
+	result = append(result, entry...)
+	result = append(result, lines[insertAt:]...)
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// replaceYAMLBlock 替换一个键及其值所占的全部行
+func replaceYAMLBlock(lines []string, keyNode *yaml.Node, key string, value interface{}) ([]byte, error) {
+	keyLine := keyNode.Line - 1
+	prefix := lines[keyLine][:runeColumnToByte(lines[keyLine], keyNode.Column)]
+	// 键前只允许缩进或序列元素标记（"- key: value"）
+	if trimmed := strings.TrimSpace(prefix); trimmed != "" && trimmed != "-" {
+		return nil, fmt.Errorf("cannot replace value of '%s' in a flow-style or inline collection", key)
+	}
+
+	entry, err := renderYAMLEntry(key, value, len(prefix))
+	if err != nil {
+		return nil, err
+	}
+	entry[0] = prefix + strings.TrimLeft(entry[0], " ")
+
+	end := yamlBlockEnd(lines, keyLine, len(prefix))
+	result := append([]string{}, lines[:keyLine]...)
+	result = append(result, entry...)
+	result = append(result, lines[end+1:]...)
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// LocateConfigKey 返回键在配置文件中的行号（从 1 开始）以及实际匹配到的路径深度；
+// 键不存在时返回最接近的父级所在行
+func LocateConfigKey(path string, data []byte, keyPath []string) (int, int) {
+	if isYAMLFile(path) {
+		node, err := parseYAMLTree(data)
+		if err != nil {
+			return 0, 0
+		}
+		line, depth := node.Line, 0
+		for _, key := range keyPath {
+			keyNode, valueNode := yamlChild(node, key)
+			if valueNode == nil {
+				break
+			}
+			if keyNode != nil {
+				line = keyNode.Line
+			} else {
+				line = valueNode.Line
+			}
+			node = valueNode
+			depth++
+		}
+		return line, depth
+	}
+
+	node, err := parseJSONTree(data)
+	if err != nil {
+		return 0, 0
+	}
+	offset, depth := node.start, 0
+	for _, key := range keyPath {
+		next, index := node.child(key)
+		if next == nil {
+			break
+		}
+		if node.kind == '{' {
+			offset = node.members[index].keyStart
+		} else {
+			offset = next.start
+		}
+		node = next
+		depth++
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1, depth
+}
@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestSetConfigValueJSON(t *testing.T) {
+	input := `{
+    "title": "My Blog",
+    "nav": {
+        "home": "/"
+    },
+    "tags": [1, 2]
+}
+`
+	// 替换已有的值，保留其余格式
+	output, err := SetConfigValue("site.json", []byte(input), []string{"title"}, "New & Blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+    "title": "New & Blog",
+    "nav": {
+        "home": "/"
+    },
+    "tags": [1, 2]
+}
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// 插入不存在的嵌套键
+	output, err = SetConfigValue("site.json", []byte(input), []string{"nav", "about"}, "/about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{
+    "title": "My Blog",
+    "nav": {
+        "home": "/",
+        "about": "/about"
+    },
+    "tags": [1, 2]
+}
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// 按下标修改数组元素
+	output, err = SetConfigValue("site.json", []byte(input), []string{"tags", "1"}, float64(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseConfigData("site.json", output); err != nil {
+		t.Errorf("output is not valid JSON: %v", err)
+	}
+}
+
+func TestSetConfigValueYAML(t *testing.T) {
+	input := `# site settings
+title: My Blog # shown in header
+nav:
+  home: /
+  about: /about
+menu:
+- name: Home
+  url: /
+footer: "old"
+`
+	output, err := SetConfigValue("site.yaml", []byte(input), []string{"title"}, "New Blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# site settings
+title: New Blog # shown in header
+nav:
+  home: /
+  about: /about
+menu:
+- name: Home
+  url: /
+footer: "old"
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"nav", "blog"}, "/blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `# site settings
+title: My Blog # shown in header
+nav:
+  home: /
+  about: /about
+  blog: /blog
+menu:
+- name: Home
+  url: /
+footer: "old"
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"menu", "0", "url"}, "/home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseConfigData("site.yaml", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := LookupConfigValue(doc, []string{"menu", "0", "url"}); value != "/home" {
+		t.Errorf("expected menu.0.url to be /home, got %v", value)
+	}
+	if value, _ := LookupConfigValue(doc, []string{"footer"}); value != "old" {
+		t.Errorf("expected footer to be unchanged, got %v", value)
+	}
+
+	// 将标量替换为对象
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"nav"}, map[string]interface{}{"home": "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = ParseConfigData("site.yaml", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := LookupConfigValue(doc, []string{"nav", "about"}); ok {
+		t.Errorf("expected nav.about to be removed:\n%s", output)
+	}
+	if _, ok := LookupConfigValue(doc, []string{"menu", "0", "name"}); !ok {
+		t.Errorf("expected menu to be unchanged:\n%s", output)
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := map[string]interface{}{
+		"title": "A",
+		"nav":   map[string]interface{}{"home": "/", "about": "/about"},
+	}
+	override := map[string]interface{}{
+		"nav": map[string]interface{}{"about": "/me"},
+	}
+
+	merged := DeepMerge(base, override)
+	if value, _ := LookupConfigValue(merged, []string{"nav", "about"}); value != "/me" {
+		t.Errorf("expected nav.about to be overridden, got %v", value)
+	}
+	if value, _ := LookupConfigValue(merged, []string{"nav", "home"}); value != "/" {
+		t.Errorf("expected nav.home to be kept, got %v", value)
+	}
+	if value, _ := LookupConfigValue(merged, []string{"title"}); value != "A" {
+		t.Errorf("expected title to be kept, got %v", value)
+	}
+}
+
+func TestSetConfigValueNumbers(t *testing.T) {
+	input := `site:
+  id: 1
+`
+	output, err := SetConfigValue("site.yaml", []byte(input), []string{"site", "id"}, ParseConfigValueArg("1000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "site:\n  id: 1000000\n"; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// 超出 float64 精度的整数保持原样
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"site", "id"}, ParseConfigValueArg("9007199254740993"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "site:\n  id: 9007199254740993\n"; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"site", "views"}, ParseConfigValueArg("2.5e6"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "site:\n  id: 1\n  views: 2500000\n"; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output, err = SetConfigValue("site.yaml", []byte(input), []string{"ratio"}, ParseConfigValueArg(`{"scale": 0.25, "max": 3000000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "site:\n  id: 1\nratio:\n  max: 3000000\n  scale: 0.25\n"; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output, err = SetConfigValue("site.json", []byte(`{"id": 1}`), []string{"id"}, ParseConfigValueArg("1000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"id": 1000000}`; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestParseConfigValueArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want interface{}
+	}{
+		{arg: "12", want: int64(12)},
+		{arg: "1.5", want: 1.5},
+		{arg: "true", want: true},
+		{arg: `"quoted"`, want: "quoted"},
+		{arg: "plain text", want: "plain text"},
+		{arg: "1 2", want: "1 2"},
+	}
+	for _, tt := range tests {
+		if got := ParseConfigValueArg(tt.arg); got != tt.want {
+			t.Errorf("ParseConfigValueArg(%q) = %#v, want %#v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestSetConfigValueYAMLFlowStyle(t *testing.T) {
+	input := "site: {title: Old, lang: en} # flow\ntags: [a, b]\n"
+	tests := []struct {
+		keyPath  []string
+		value    interface{}
+		expected string
+	}{
+		{[]string{"site", "title"}, "New", "site: {title: New, lang: en} # flow\ntags: [a, b]\n"},
+		{[]string{"site", "lang"}, "zh", "site: {title: Old, lang: zh} # flow\ntags: [a, b]\n"},
+		{[]string{"site", "title"}, "A, B", "site: {title: \"A, B\", lang: en} # flow\ntags: [a, b]\n"},
+		{[]string{"tags", "1"}, "c", "site: {title: Old, lang: en} # flow\ntags: [a, c]\n"},
+	}
+	for _, test := range tests {
+		output, err := SetConfigValue("site.yaml", []byte(input), test.keyPath, test.value)
+		if err != nil {
+			t.Errorf("%v: %v", test.keyPath, err)
+			continue
+		}
+		if string(output) != test.expected {
+			t.Errorf("%v: unexpected output:\n%s", test.keyPath, output)
+		}
+	}
+}
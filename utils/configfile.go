@@ -0,0 +1,346 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsConfigFile 判断文件是否为支持的配置文件格式（JSON/YAML）
+func IsConfigFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// isYAMLFile 判断文件是否为 YAML 格式
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ReadConfigFile 读取 JSON 或 YAML 配置文件
+func ReadConfigFile(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfigData(path, data)
+}
+
+// ParseConfigData 按文件扩展名解析配置内容
+func ParseConfigData(path string, data []byte) (interface{}, error) {
+	var value interface{}
+	if isYAMLFile(path) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+		}
+		if doc.Kind != yaml.DocumentNode {
+			return nil, nil
+		}
+		// 日期没有对应的 JSON 类型，按原文作为字符串，否则会被解析为 time.Time
+		retagTimestamps(&doc)
+		if err := doc.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+		}
+		return normalizeYAMLValue(value), nil
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	return value, nil
+}
+
+// MergeConfigData 将 override 的内容深度合并到 base 上并按 path 的格式序列化：
+// 对象逐键合并，其余类型（包括数组）直接替换；保留 base 的键顺序、注释和数字的原始写法
+func MergeConfigData(path string, base, override []byte) ([]byte, error) {
+	baseNode, err := parseConfigNode(path, base)
+	if err != nil {
+		return nil, err
+	}
+	overrideNode, err := parseConfigNode(path, override)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged *yaml.Node
+	switch {
+	case baseNode == nil:
+		merged = overrideNode
+	case overrideNode == nil:
+		merged = baseNode
+	default:
+		merged = mergeConfigNode(baseNode, overrideNode)
+	}
+
+	if isYAMLFile(path) {
+		return encodeYAMLDocument(base, merged)
+	}
+	var buf bytes.Buffer
+	if merged != nil {
+		if err := writeJSONNode(&buf, merged, "", detectJSONIndent(base)); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// parseConfigNode 将配置内容解析为 yaml.Node 树，空文档返回 nil
+func parseConfigNode(path string, data []byte) (*yaml.Node, error) {
+	if isYAMLFile(path) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+		}
+		if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+			return nil, nil
+		}
+		return doc.Content[0], nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeJSONNode(decoder)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return node, nil
+		} else if err == nil {
+			err = fmt.Errorf("unexpected trailing content")
+		}
+	}
+	return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+}
+
+// decodeJSONNode 按顺序读取一个 JSON 值并转换为 yaml.Node，数字保留原始写法
+func decodeJSONNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			item, err := decodeJSONNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		// 读取结尾的 } 或 ]
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+}
+
+// mergeConfigNode 将 override 深度合并到 base 上：映射逐键合并，新键追加在末尾，其余节点直接替换；
+// 替换的值没有行尾注释时沿用原值的注释
+func mergeConfigNode(base, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		if override.LineComment == "" {
+			override.LineComment = base.LineComment
+		}
+		return override
+	}
+
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				base.Content[j+1] = mergeConfigNode(base.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
+
+// encodeYAMLDocument 按原文件的缩进序列化 YAML 节点，保留原文档的头尾注释
+func encodeYAMLDocument(original []byte, node *yaml.Node) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil || doc.Kind != yaml.DocumentNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	doc.Content = nil
+	if node != nil {
+		doc.Content = []*yaml.Node{node}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(detectYAMLIndent(original))
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// detectYAMLIndent 推测 YAML 文件使用的缩进宽度，默认为 2
+func detectYAMLIndent(data []byte) int {
+	indent := 0
+	for _, line := range splitLines(data) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if n := indentOf(line); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return 2
+	}
+	return indent
+}
+
+// writeJSONNode 将由 JSON 解析得到的节点序列化为缩进格式
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent, unit string) error {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		openDelim, closeDelim := "[", "]"
+		step := 1
+		if node.Kind == yaml.MappingNode {
+			openDelim, closeDelim = "{", "}"
+			step = 2
+		}
+		if len(node.Content) == 0 {
+			buf.WriteString(openDelim + closeDelim)
+			return nil
+		}
+		buf.WriteString(openDelim + "\n")
+		for i := 0; i+step-1 < len(node.Content); i += step {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + unit)
+			if step == 2 {
+				key, err := marshalJSONAt(node.Content[i].Value, "", "")
+				if err != nil {
+					return err
+				}
+				buf.Write(key)
+				buf.WriteString(": ")
+			}
+			if err := writeJSONNode(buf, node.Content[i+step-1], indent+unit, unit); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + closeDelim)
+	default:
+		if node.Tag != "!!str" {
+			buf.WriteString(node.Value)
+			return nil
+		}
+		encoded, err := marshalJSONAt(node.Value, "", "")
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
+
+// retagTimestamps 将节点树中的时间戳标量标记为字符串
+func retagTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		retagTimestamps(child)
+	}
+}
+
+// normalizeYAMLValue 将 YAML 解析出的 map[interface{}]interface{} 等统一为 JSON 风格的结构
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return value
+}
+
+// DeepMerge 将 override 深度合并到 base 上：对象逐键合并，其余类型（包括数组）直接替换
+func DeepMerge(base, override interface{}) interface{} {
+	baseMap, baseOK := base.(map[string]interface{})
+	overrideMap, overrideOK := override.(map[string]interface{})
+	if !baseOK || !overrideOK {
+		return override
+	}
+
+	result := make(map[string]interface{}, len(baseMap)+len(overrideMap))
+	for key, value := range baseMap {
+		result[key] = value
+	}
+	for key, value := range overrideMap {
+		if existing, ok := result[key]; ok {
+			result[key] = DeepMerge(existing, value)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfigDataYAML(t *testing.T) {
+	base := `# site settings
+site:
+  title: My Blog # shown in header
+  id: 12345678
+  nav:
+    home: /
+    about: /about
+tags:
+  - a
+  - b
+ratio: 0.5
+`
+	override := `site:
+  id: 87654321
+  nav:
+    about: /me
+tags:
+  - c
+extra: 1000000
+`
+	output, err := MergeConfigData("site.yaml", []byte(base), []byte(override))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# site settings
+site:
+  title: My Blog # shown in header
+  id: 87654321
+  nav:
+    home: /
+    about: /me
+tags:
+  - c
+ratio: 0.5
+extra: 1000000
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestMergeConfigDataYAMLKeepsComment(t *testing.T) {
+	base := "title: My Blog # shown in header\n"
+	output, err := MergeConfigData("site.yml", []byte(base), []byte("title: New Blog\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "title: New Blog # shown in header\n"; string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// 覆盖层为空时保留原文件内容
+	output, err = MergeConfigData("site.yml", []byte(base), []byte("# nothing\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != base {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestMergeConfigDataJSON(t *testing.T) {
+	base := `{
+    "title": "My Blog",
+    "id": 12345678,
+    "nav": {
+        "home": "/",
+        "about": "/about"
+    },
+    "tags": ["a", "b"],
+    "empty": {}
+}
+`
+	override := `{"nav": {"about": "/me & you"}, "tags": [1e3], "id": 87654321, "draft": false}`
+	output, err := MergeConfigData("site.json", []byte(base), []byte(override))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+    "title": "My Blog",
+    "id": 87654321,
+    "nav": {
+        "home": "/",
+        "about": "/me & you"
+    },
+    "tags": [
+        1e3
+    ],
+    "empty": {},
+    "draft": false
+}
+`
+	if string(output) != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	if _, err := MergeConfigData("site.json", []byte(base), []byte(`{"a": 1} {}`)); err == nil {
+		t.Error("expected trailing content to be rejected")
+	}
+}
+
+func TestParseConfigDataKeepsDatesAsStrings(t *testing.T) {
+	data := "date: 2024-01-01\nupdated: 2024-01-01T10:00:00Z\ncount: 3\n2024-02-02: key\n"
+	value, err := ParseConfigData("site.yaml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"date":       "2024-01-01",
+		"updated":    "2024-01-01T10:00:00Z",
+		"count":      float64(3),
+		"2024-02-02": "key",
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("unexpected value: %#v", value)
+	}
+
+	// 日期需要通过 "type": "string" 的校验
+	schema := &Schema{}
+	if err := json.Unmarshal([]byte(`{"type": "object", "properties": {"date": {"type": "string"}}}`), &schema.root); err != nil {
+		t.Fatal(err)
+	}
+	if errs := schema.Validate(value); len(errs) > 0 {
+		t.Errorf("unexpected schema errors: %v", errs)
+	}
+}
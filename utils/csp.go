@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// cspDirectiveOrder 是输出 CSP 时指令的顺序，其余指令按字母顺序排在后面
+var cspDirectiveOrder = []string{
+	"default-src", "script-src", "script-src-attr", "style-src", "style-src-attr", "img-src", "font-src",
+	"connect-src", "media-src", "frame-src", "manifest-src", "worker-src", "object-src", "base-uri", "form-action", "frame-ancestors",
+}
+
+// CSP 是按指令收集来源的 Content-Security-Policy
+type CSP struct {
+	directives map[string][]string
+}
+
+// NewCSP 创建空的 CSP
+func NewCSP() *CSP {
+	return &CSP{directives: make(map[string][]string)}
+}
+
+// Add 为指令添加来源，重复的来源只保留一个；不带来源时只声明指令
+func (c *CSP) Add(directive string, sources ...string) {
+	existing, ok := c.directives[directive]
+	if !ok {
+		c.directives[directive] = []string{}
+	}
+	for _, source := range sources {
+		found := false
+		for _, s := range existing {
+			if s == source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, source)
+		}
+	}
+	if len(existing) > 0 {
+		c.directives[directive] = existing
+	}
+}
+
+// Has 判断是否声明了指令
+func (c *CSP) Has(directive string) bool {
+	_, ok := c.directives[directive]
+	return ok
+}
+
+// String 返回 CSP 头的值；关键字来源排在主机来源和哈希之前
+func (c *CSP) String() string {
+	var names []string
+	for _, name := range cspDirectiveOrder {
+		if c.Has(name) {
+			names = append(names, name)
+		}
+	}
+	var others []string
+	for name := range c.directives {
+		if !Contains(cspDirectiveOrder, name) {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	var parts []string
+	for _, name := range names {
+		sources := append([]string(nil), c.directives[name]...)
+		sort.SliceStable(sources, func(i, j int) bool {
+			return cspSourceRank(sources[i]) < cspSourceRank(sources[j])
+		})
+		parts = append(parts, strings.TrimSpace(name+" "+strings.Join(sources, " ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// cspSourceRank 决定来源的排列顺序：关键字、scheme、主机、哈希
+func cspSourceRank(source string) int {
+	switch {
+	case strings.HasPrefix(source, "'sha"):
+		return 3
+	case strings.HasPrefix(source, "'"):
+		return 0
+	case strings.HasSuffix(source, ":"):
+		return 1
+	}
+	return 2
+}
+
+// CSPHash 返回内联脚本或样式的 CSP 哈希来源，如 'sha256-...'
+func CSPHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// CSPSource 返回 URL 对应的 CSP 来源：其他站点的地址返回 scheme://host，data:、blob: 返回 scheme；
+// 相对地址等站内地址返回 false
+func CSPSource(rawURL string) (string, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return "", false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	switch scheme {
+	case "http", "https", "ws", "wss":
+		if u.Host == "" {
+			return "", false
+		}
+		return scheme + "://" + strings.ToLower(u.Host), true
+	case "data", "blob":
+		return scheme + ":", true
+	}
+	return "", false
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "regexp"
+
+var (
+	cssURLPattern    = regexp.MustCompile(`url\(\s*(["']?)([^"')]*)(["']?)\s*\)`)
+	cssImportPattern = regexp.MustCompile(`@import\s+(["'])([^"']*)(["'])`)
+)
+
+// RewriteCSSURLs 替换 CSS 中 url(...) 和 @import "..." 引用的 URL，其余内容保持不变
+func RewriteCSSURLs(data []byte, rewrite URLRewriter) []byte {
+	replace := func(pattern *regexp.Regexp, data []byte) []byte {
+		return pattern.ReplaceAllFunc(data, func(match []byte) []byte {
+			groups := pattern.FindSubmatchIndex(match)
+			url := string(match[groups[4]:groups[5]])
+			if url == "" || string(match[groups[2]:groups[3]]) != string(match[groups[6]:groups[7]]) {
+				return match
+			}
+			newURL, ok := rewrite(url)
+			if !ok {
+				return match
+			}
+			out := append([]byte(nil), match[:groups[4]]...)
+			out = append(out, newURL...)
+			return append(out, match[groups[5]:]...)
+		})
+	}
+	return replace(cssImportPattern, replace(cssURLPattern, data))
+}
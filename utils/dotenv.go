@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EnvVar 是 .env 文件中的一个变量
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// secretKeyPattern 匹配通常保存密钥的变量名
+var secretKeyPattern = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|PRIVATE|CREDENTIAL|API_?KEY|ACCESS_?KEY|AUTH)`)
+
+// IsSecretEnvKey 判断变量名是否看起来保存了密钥
+func IsSecretEnvKey(key string) bool {
+	return secretKeyPattern.MatchString(key)
+}
+
+// ParseDotEnv 解析 .env 文件内容，支持 export 前缀、单双引号、
+// 双引号中的转义字符、跨行的引号值以及行尾注释
+func ParseDotEnv(data []byte) ([]EnvVar, error) {
+	var vars []EnvVar
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name '%s'", lineNum, key)
+		}
+		value := strings.TrimSpace(line[eq+1:])
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			quote := value[0]
+			// 引号值可以跨行，读到配对的结束引号为止
+			for !hasClosingQuote(value, quote) {
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated quoted value for '%s'", lineNum, key)
+				}
+				lineNum++
+				value += "\n" + scanner.Text()
+			}
+			end := closingQuoteIndex(value, quote)
+			value = value[1:end]
+			if quote == '"' {
+				value = unescapeDoubleQuoted(value)
+			}
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		vars = append(vars, EnvVar{Key: key, Value: value})
+	}
+	return vars, scanner.Err()
+}
+
+// closingQuoteIndex 返回与开头引号配对的结束引号位置，没有时返回 -1
+func closingQuoteIndex(value string, quote byte) int {
+	for i := 1; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasClosingQuote(value string, quote byte) bool {
+	return closingQuoteIndex(value, quote) > 0
+}
+
+// unescapeDoubleQuoted 处理双引号值中的转义字符
+func unescapeDoubleQuoted(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import "testing"
+
+func TestParseDotEnv(t *testing.T) {
+	data := []byte(`# comment
+SITE_URL=https://example.com
+export API_TOKEN = abc123
+EMPTY=
+INLINE=value # trailing comment
+HASH=a#b
+SINGLE='raw $value \n # kept'
+DOUBLE="line1\nline2 \"quoted\""
+MULTI="first
+second"
+`)
+
+	vars, err := ParseDotEnv(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []EnvVar{
+		{"SITE_URL", "https://example.com"},
+		{"API_TOKEN", "abc123"},
+		{"EMPTY", ""},
+		{"INLINE", "value"},
+		{"HASH", "a#b"},
+		{"SINGLE", `raw $value \n # kept`},
+		{"DOUBLE", "line1\nline2 \"quoted\""},
+		{"MULTI", "first\nsecond"},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d vars, want %d: %v", len(vars), len(want), vars)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("var %d = %q, want %q", i, vars[i], want[i])
+		}
+	}
+}
+
+func TestParseDotEnvErrors(t *testing.T) {
+	for _, data := range []string{"NOVALUE\n", "1BAD=x\n", "OPEN=\"never closed\n"} {
+		if _, err := ParseDotEnv([]byte(data)); err == nil {
+			t.Errorf("ParseDotEnv(%q) should fail", data)
+		}
+	}
+}
+
+func TestIsSecretEnvKey(t *testing.T) {
+	for _, key := range []string{"API_TOKEN", "DB_PASSWORD", "STRIPE_SECRET_KEY", "ALGOLIA_API_KEY", "GITHUB_AUTH"} {
+		if !IsSecretEnvKey(key) {
+			t.Errorf("Expected %s to be a secret", key)
+		}
+	}
+	for _, key := range []string{"SITE_URL", "BASE_PATH", "KEYWORDS_LIMIT"} {
+		if IsSecretEnvKey(key) {
+			t.Errorf("Expected %s not to be a secret", key)
+		}
+	}
+}
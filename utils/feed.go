@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed 是生成 RSS、Atom 和 JSON Feed 共用的内容
+type Feed struct {
+	Title       string
+	Description string
+	Language    string
+	Author      string
+	HomeURL     string // 站点首页的绝对地址
+	Updated     time.Time
+	Items       []FeedItem
+}
+
+// FeedItem 是订阅中的一篇文章
+type FeedItem struct {
+	URL       string // 文章的绝对地址，同时作为 ID
+	Title     string
+	Summary   string
+	Published time.Time
+	Updated   time.Time // 没有修改时间时与 Published 相同
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XmlnsAtom string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	Self          rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RenderRSS 生成 RSS 2.0，selfURL 是订阅文件自身的地址
+func RenderRSS(feed Feed, selfURL string) ([]byte, error) {
+	rss := rssFeed{
+		Version:   "2.0",
+		XmlnsAtom: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.HomeURL,
+			Description:   feed.Description,
+			Language:      feed.Language,
+			LastBuildDate: feed.Updated.Format(time.RFC1123Z),
+			Self:          rssAtomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	// RSS 要求频道有描述
+	if rss.Channel.Description == "" {
+		rss.Channel.Description = feed.Title
+	}
+	for _, item := range feed.Items {
+		rss.Channel.Items = append(rss.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.URL},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Description: item.Summary,
+		})
+	}
+	return marshalXML(rss)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	Link      atomLink `xml:"link"`
+	ID        string   `xml:"id"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary,omitempty"`
+}
+
+// RenderAtom 生成 Atom 1.0，selfURL 是订阅文件自身的地址；没有作者时使用订阅标题
+func RenderAtom(feed Feed, selfURL string) ([]byte, error) {
+	atom := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Lang:     feed.Language,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Links: []atomLink{
+			{Href: feed.HomeURL},
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		ID:      feed.HomeURL,
+		Updated: feed.Updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: feed.Author},
+	}
+	if atom.Author.Name == "" {
+		atom.Author.Name = feed.Title
+	}
+	for _, item := range feed.Items {
+		atom.Entries = append(atom.Entries, atomEntry{
+			Title:     item.Title,
+			Link:      atomLink{Href: item.URL},
+			ID:        item.URL,
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Summary:   item.Summary,
+		})
+	}
+	return marshalXML(atom)
+}
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	Summary       string `json:"summary,omitempty"`
+	ContentText   string `json:"content_text"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// RenderJSONFeed 生成 JSON Feed 1.1，selfURL 是订阅文件自身的地址
+func RenderJSONFeed(feed Feed, selfURL string) ([]byte, error) {
+	out := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.HomeURL,
+		FeedURL:     selfURL,
+		Description: feed.Description,
+		Language:    feed.Language,
+		Items:       []jsonFeedItem{},
+	}
+	if feed.Author != "" {
+		out.Authors = []jsonFeedAuthor{{Name: feed.Author}}
+	}
+	for _, item := range feed.Items {
+		// JSON Feed 要求每项有 content_html 或 content_text
+		out.Items = append(out.Items, jsonFeedItem{
+			ID:            item.URL,
+			URL:           item.URL,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			ContentText:   item.Summary,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+		})
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalXML 以带 XML 声明的缩进格式编码
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append([]byte(xml.Header), data...)
+	return append(data, '\n'), nil
+}
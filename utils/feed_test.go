@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderFeeds(t *testing.T) {
+	published := time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("", 8*3600))
+	feed := Feed{
+		Title:   "我的博客",
+		HomeURL: "https://example.com/blog/",
+		Updated: published,
+		Items: []FeedItem{
+			{URL: "https://example.com/blog/posts/a/", Title: "A & B", Summary: "摘要", Published: published, Updated: published},
+		},
+	}
+
+	rss, err := RenderRSS(feed, "https://example.com/blog/feed.xml")
+	if err != nil {
+		t.Fatalf("RenderRSS() error = %v", err)
+	}
+	for _, want := range []string{`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`, `<description>我的博客</description>`,
+		`<atom:link href="https://example.com/blog/feed.xml" rel="self" type="application/rss+xml"></atom:link>`,
+		`<title>A &amp; B</title>`, `<pubDate>Fri, 01 Mar 2024 10:00:00 +0800</pubDate>`} {
+		if !strings.Contains(string(rss), want) {
+			t.Errorf("RSS does not contain %s:\n%s", want, rss)
+		}
+	}
+
+	atom, err := RenderAtom(feed, "https://example.com/blog/atom.xml")
+	if err != nil {
+		t.Fatalf("RenderAtom() error = %v", err)
+	}
+	for _, want := range []string{`<feed xmlns="http://www.w3.org/2005/Atom">`, `<name>我的博客</name>`,
+		`<id>https://example.com/blog/posts/a/</id>`, `<published>2024-03-01T10:00:00+08:00</published>`} {
+		if !strings.Contains(string(atom), want) {
+			t.Errorf("Atom does not contain %s:\n%s", want, atom)
+		}
+	}
+
+	data, err := RenderJSONFeed(feed, "https://example.com/blog/feed.json")
+	if err != nil {
+		t.Fatalf("RenderJSONFeed() error = %v", err)
+	}
+	var parsed struct {
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ID            string `json:"id"`
+			DatePublished string `json:"date_published"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("JSON Feed is not valid JSON: %v", err)
+	}
+	if parsed.FeedURL != "https://example.com/blog/feed.json" || len(parsed.Items) != 1 || parsed.Items[0].DatePublished != "2024-03-01T10:00:00+08:00" {
+		t.Errorf("RenderJSONFeed() = %s", data)
+	}
+}
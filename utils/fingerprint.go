@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Fingerprint 按固定顺序累加文件路径和内容的 SHA-256 指纹
+type Fingerprint struct {
+	h hash.Hash
+}
+
+// NewFingerprint 创建空指纹
+func NewFingerprint() *Fingerprint {
+	return &Fingerprint{h: sha256.New()}
+}
+
+// AddString 将一个命名的值加入指纹
+func (f *Fingerprint) AddString(name, value string) {
+	fmt.Fprintf(f.h, "value %q %q\n", name, value)
+}
+
+// AddTree 将目录中的全部文件加入指纹，路径以 prefix 开头记录；
+// skip 返回 true 的文件或目录会被跳过。目录不存在时不加入任何内容
+func (f *Fingerprint) AddTree(root, prefix string, skip func(path string, info os.FileInfo) bool) error {
+	if !FileExists(root) {
+		return nil
+	}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && skip != nil && skip(path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(prefix, relPath))
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(f.h, "link %q %q\n", name, target)
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		sum, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(f.h, "file %q %o %s\n", name, info.Mode().Perm()&0111, sum)
+		return nil
+	})
+}
+
+// Sum 返回十六进制的指纹
+func (f *Fingerprint) Sum() string {
+	return hex.EncodeToString(f.h.Sum(nil))
+}
+
+// HashFile 计算单个文件内容的 SHA-256
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func treeFingerprint(t *testing.T, root string) string {
+	t.Helper()
+	f := NewFingerprint()
+	err := f.AddTree(root, "theme", func(path string, info os.FileInfo) bool {
+		return info.IsDir() && info.Name() == "node_modules"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Sum()
+}
+
+func TestFingerprintAddTree(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("src/index.js", "a")
+	write("config/site.json", "{}")
+	base := treeFingerprint(t, root)
+
+	if got := treeFingerprint(t, root); got != base {
+		t.Error("Expected the same fingerprint for an unchanged tree")
+	}
+
+	write("node_modules/dep/index.js", "ignored")
+	if got := treeFingerprint(t, root); got != base {
+		t.Error("Expected skipped directories not to change the fingerprint")
+	}
+
+	write("src/index.js", "b")
+	if got := treeFingerprint(t, root); got == base {
+		t.Error("Expected a changed file to change the fingerprint")
+	}
+
+	write("src/index.js", "a")
+	os.Rename(filepath.Join(root, "src", "index.js"), filepath.Join(root, "src", "main.js"))
+	if got := treeFingerprint(t, root); got == base {
+		t.Error("Expected a renamed file to change the fingerprint")
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.js", "app.js", true},
+		{"*.js", "assets/js/app.js", true},
+		{"*.js", "app.json", false},
+		{"assets/*.css", "assets/main.css", true},
+		{"assets/*.css", "assets/css/main.css", false},
+		{"assets/**/*.css", "assets/main.css", true},
+		{"assets/**/*.css", "assets/css/vendor/main.css", true},
+		{"**/index.html", "index.html", true},
+		{"**/index.html", "posts/hello/index.html", true},
+		{"/images/*", "images/a.png", true},
+		{"images/*", "other/images/a.png", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redirect 是一条重定向规则；From 中结尾的 * 匹配任意后缀，To 中的 :splat 替换为匹配到的部分
+type Redirect struct {
+	From   string
+	To     string
+	Status int
+}
+
+// HeaderRule 是为匹配 Path 的请求添加的响应头；Path 中的 * 匹配任意字符
+type HeaderRule struct {
+	Path   string
+	Values map[string]string
+}
+
+// RenderNetlifyRedirects 生成 Netlify / Cloudflare Pages 的 _redirects 文件内容
+func RenderNetlifyRedirects(redirects []Redirect) []byte {
+	var b strings.Builder
+	for _, r := range redirects {
+		fmt.Fprintf(&b, "%s %s %d\n", r.From, r.To, r.Status)
+	}
+	return []byte(b.String())
+}
+
+// RenderNetlifyHeaders 生成 Netlify / Cloudflare Pages 的 _headers 文件内容，同一规则中的头按名称排序
+func RenderNetlifyHeaders(rules []HeaderRule) []byte {
+	var b strings.Builder
+	for i, rule := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(rule.Path + "\n")
+		for _, name := range sortedHeaderNames(rule.Values) {
+			fmt.Fprintf(&b, "  %s: %s\n", name, rule.Values[name])
+		}
+	}
+	return []byte(b.String())
+}
+
+// RenderNginx 生成可以 include 到 server 块中的 nginx 配置。
+// 路径为 /* 的响应头写在 server 级别；nginx 的 location 中一旦有 add_header，就不再继承 server 级别的头，
+// 因此这些头会同时写入每个 location
+func RenderNginx(redirects []Redirect, rules []HeaderRule) []byte {
+	var b strings.Builder
+	var global []HeaderRule
+	var located []HeaderRule
+	for _, rule := range rules {
+		if rule.Path == "/*" {
+			global = append(global, rule)
+		} else {
+			located = append(located, rule)
+		}
+	}
+
+	writeHeaders := func(indent string, rules []HeaderRule) {
+		for _, rule := range rules {
+			for _, name := range sortedHeaderNames(rule.Values) {
+				fmt.Fprintf(&b, "%sadd_header %s %s always;\n", indent, name, nginxQuote(rule.Values[name]))
+			}
+		}
+	}
+
+	writeHeaders("", global)
+	for _, r := range redirects {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		location, hasSplat := nginxLocation(r.From)
+		to := r.To
+		if hasSplat {
+			to = strings.ReplaceAll(to, ":splat", "$1")
+		}
+		fmt.Fprintf(&b, "location %s {\n    return %d %s;\n}\n", location, r.Status, nginxQuote(to))
+	}
+	for _, rule := range located {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		location, _ := nginxLocation(rule.Path)
+		fmt.Fprintf(&b, "location %s {\n", location)
+		writeHeaders("    ", global)
+		writeHeaders("    ", []HeaderRule{rule})
+		b.WriteString("}\n")
+	}
+	return []byte(b.String())
+}
+
+// RenderRedirectStub 生成跳转到 to 的 HTML 页面，用于不支持服务器端重定向的静态托管
+func RenderRedirectStub(to string) []byte {
+	escaped := html.EscapeString(to)
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Redirecting…</title>
+<meta name="robots" content="noindex">
+<link rel="canonical" href="%s">
+<meta http-equiv="refresh" content="0; url=%s">
+</head>
+<body>
+<p>This page has moved to <a href="%s">%s</a>.</p>
+</body>
+</html>
+`, escaped, escaped, escaped, escaped))
+}
+
+// nginxLocation 将路径模式转换为 nginx 的 location 参数：没有 * 时精确匹配，只在结尾有 * 时按前缀匹配，
+// 其余情况使用正则。第二个返回值表示结尾的 * 是否被捕获为 $1
+func nginxLocation(pattern string) (string, bool) {
+	if !strings.Contains(pattern, "*") {
+		return "= " + nginxQuote(pattern), false
+	}
+	if strings.Count(pattern, "*") == 1 && strings.HasSuffix(pattern, "*") {
+		return "~ " + nginxQuote("^"+regexp.QuoteMeta(strings.TrimSuffix(pattern, "*"))+"(.*)$"), true
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "~ " + nginxQuote("^"+strings.Join(parts, ".*")+"$"), false
+}
+
+// nginxQuote 在值包含空白、引号、分号或花括号时加上双引号
+func nginxQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"';{}") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// sortedHeaderNames 返回排序后的响应头名称
+func sortedHeaderNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHostingConfig(t *testing.T) {
+	redirects := []Redirect{
+		{From: "/old/", To: "/new/", Status: 301},
+		{From: "/docs/*", To: "https://docs.example.com/:splat", Status: 302},
+	}
+	rules := []HeaderRule{
+		{Path: "/*", Values: map[string]string{"X-Frame-Options": "DENY", "Content-Security-Policy": "default-src 'self'"}},
+		{Path: "/assets/*", Values: map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+	}
+
+	if got, want := string(RenderNetlifyRedirects(redirects)), "/old/ /new/ 301\n/docs/* https://docs.example.com/:splat 302\n"; got != want {
+		t.Errorf("RenderNetlifyRedirects() = %q, want %q", got, want)
+	}
+
+	wantHeaders := `/*
+  Content-Security-Policy: default-src 'self'
+  X-Frame-Options: DENY
+
+/assets/*
+  Cache-Control: public, max-age=31536000, immutable
+`
+	if got := string(RenderNetlifyHeaders(rules)); got != wantHeaders {
+		t.Errorf("RenderNetlifyHeaders() =\n%s\nwant\n%s", got, wantHeaders)
+	}
+
+	wantNginx := `add_header Content-Security-Policy "default-src 'self'" always;
+add_header X-Frame-Options DENY always;
+
+location = /old/ {
+    return 301 /new/;
+}
+
+location ~ ^/docs/(.*)$ {
+    return 302 https://docs.example.com/$1;
+}
+
+location ~ ^/assets/(.*)$ {
+    add_header Content-Security-Policy "default-src 'self'" always;
+    add_header X-Frame-Options DENY always;
+    add_header Cache-Control "public, max-age=31536000, immutable" always;
+}
+`
+	if got := string(RenderNginx(redirects, rules)); got != wantNginx {
+		t.Errorf("RenderNginx() =\n%s\nwant\n%s", got, wantNginx)
+	}
+
+	stub := string(RenderRedirectStub("/new/?a=1&b=2"))
+	if !strings.Contains(stub, `<meta http-equiv="refresh" content="0; url=/new/?a=1&amp;b=2">`) {
+		t.Errorf("RenderRedirectStub() =\n%s", stub)
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLLink 是页面中的一个链接或资源引用
+type HTMLLink struct {
+	URL  string
+	Tag  string // 所在标签，如 a、img
+	Line int    // 所在行号，从 1 开始
+}
+
+// HTMLLinks 是页面中的全部链接和可以作为锚点的 id
+type HTMLLinks struct {
+	Links []HTMLLink
+	IDs   map[string]bool
+}
+
+// linkAttributes 是各标签中包含链接的属性
+var linkAttributes = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"script": {"src"},
+	"iframe": {"src"},
+	"embed":  {"src"},
+	"track":  {"src"},
+	"audio":  {"src"},
+	"video":  {"src", "poster"},
+	"object": {"data"},
+}
+
+// ignoredLinkRels 是不指向具体资源的 <link rel>
+var ignoredLinkRels = []string{"preconnect", "dns-prefetch"}
+
+// ParseHTMLLinks 找出页面中的链接（a、img、script、link 等）和元素的 id（包括 <a name>）
+func ParseHTMLLinks(data []byte) HTMLLinks {
+	result := HTMLLinks{IDs: make(map[string]bool)}
+	z := html.NewTokenizer(bytes.NewReader(data))
+	line := 1
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return result
+		}
+		raw := z.Raw()
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			line += bytes.Count(raw, []byte{'\n'})
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		attrs := make(map[string]string)
+		for hasAttr {
+			var key, value []byte
+			key, value, hasAttr = z.TagAttr()
+			attrs[string(key)] = string(value)
+		}
+
+		if id := attrs["id"]; id != "" {
+			result.IDs[id] = true
+		}
+		if tag == "a" && attrs["name"] != "" {
+			result.IDs[attrs["name"]] = true
+		}
+
+		skip := false
+		if tag == "link" {
+			for _, rel := range ignoredLinkRels {
+				skip = skip || HasToken(attrs["rel"], rel)
+			}
+		}
+		for _, attr := range linkAttributes[tag] {
+			value, ok := attrs[attr]
+			if !ok || skip {
+				continue
+			}
+			if attr == "srcset" {
+				for _, candidate := range strings.Split(value, ",") {
+					if fields := strings.Fields(candidate); len(fields) > 0 {
+						result.Links = append(result.Links, HTMLLink{URL: fields[0], Tag: tag, Line: line})
+					}
+				}
+				continue
+			}
+			if value = strings.TrimSpace(value); value != "" {
+				result.Links = append(result.Links, HTMLLink{URL: value, Tag: tag, Line: line})
+			}
+		}
+		line += bytes.Count(raw, []byte{'\n'})
+	}
+}
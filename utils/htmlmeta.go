@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageMeta 是 HTML 页面 <head> 中的元数据
+type PageMeta struct {
+	Lang        string // <html lang>
+	Title       string
+	Description string // <meta name="description"> 的 content
+	Canonical   string // <link rel="canonical"> 的 href
+	Robots      string // <meta name="robots"> 的 content
+	Modified    string // 修改时间：article:modified_time、og:updated_time 或 dateModified
+	Published   string // 发布时间：article:published_time 或 datePublished
+	Refresh     string // <meta http-equiv="refresh"> 的 content，跳转页面才有
+}
+
+// NoIndex 判断页面是否要求搜索引擎不收录
+func (m PageMeta) NoIndex() bool {
+	for _, value := range strings.Split(strings.ToLower(m.Robots), ",") {
+		if value = strings.TrimSpace(value); value == "noindex" || value == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePageMeta 读取页面 <head> 中的元数据，遇到 <body> 时停止
+func ParsePageMeta(data []byte) PageMeta {
+	var meta PageMeta
+	z := html.NewTokenizer(bytes.NewReader(data))
+	inTitle := false
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return meta
+		case html.TextToken:
+			if inTitle {
+				meta.Title += string(z.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "title" {
+				inTitle = false
+				meta.Title = strings.TrimSpace(meta.Title)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch string(name) {
+			case "body":
+				return meta
+			case "html":
+				meta.Lang = strings.TrimSpace(attrs["lang"])
+			case "title":
+				inTitle = tt == html.StartTagToken && meta.Title == ""
+			case "link":
+				if HasToken(attrs["rel"], "canonical") && meta.Canonical == "" {
+					meta.Canonical = strings.TrimSpace(attrs["href"])
+				}
+			case "meta":
+				key := strings.ToLower(attrs["name"] + attrs["property"] + attrs["itemprop"])
+				content := strings.TrimSpace(attrs["content"])
+				if strings.EqualFold(attrs["http-equiv"], "refresh") {
+					meta.Refresh = content
+				}
+				switch key {
+				case "description":
+					meta.Description = content
+				case "robots":
+					meta.Robots = content
+				case "article:modified_time", "og:updated_time", "datemodified":
+					if meta.Modified == "" {
+						meta.Modified = content
+					}
+				case "article:published_time", "datepublished":
+					if meta.Published == "" {
+						meta.Published = content
+					}
+				}
+			}
+		}
+	}
+}
+
+// HasToken 判断以空白分隔的属性值（如 rel）是否包含 token
+func HasToken(value, token string) bool {
+	for _, field := range strings.Fields(strings.ToLower(value)) {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageElement 是页面中的一个元素及其所在行
+type PageElement struct {
+	Line int
+	Text string // 元素的说明，如图片的 src
+}
+
+// PageOutline 是页面正文中与可访问性和 SEO 相关的元素
+type PageOutline struct {
+	H1         []PageElement // 全部 <h1>
+	MissingAlt []PageElement // 没有 alt 属性的 <img>（alt="" 表示装饰性图片，不算缺失）
+}
+
+// ParsePageOutline 找出页面中的 <h1> 和缺少 alt 属性的图片
+func ParsePageOutline(data []byte) PageOutline {
+	var outline PageOutline
+	z := html.NewTokenizer(bytes.NewReader(data))
+	line := 1
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return outline
+		}
+		raw := z.Raw()
+		newlines := bytes.Count(raw, []byte{'\n'})
+
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			name, hasAttr := z.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch string(name) {
+			case "h1":
+				outline.H1 = append(outline.H1, PageElement{Line: line})
+			case "img":
+				_, hasAlt := attrs["alt"]
+				// 对辅助技术隐藏的图片不需要 alt
+				hidden := attrs["aria-hidden"] == "true" || attrs["role"] == "presentation" || attrs["role"] == "none"
+				if !hasAlt && !hidden {
+					outline.MissingAlt = append(outline.MissingAlt, PageElement{Line: line, Text: strings.TrimSpace(attrs["src"])})
+				}
+			}
+		}
+		line += newlines
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestParsePageOutline(t *testing.T) {
+	page := `<html lang="en"><body>
+<h1>One</h1>
+<img src="/a.png">
+<img src="/b.png" alt="">
+<img src="/c.png" aria-hidden="true">
+<h1>Two</h1><img src="/d.png"
+  class="wide">
+</body></html>`
+
+	outline := ParsePageOutline([]byte(page))
+	if len(outline.H1) != 2 || outline.H1[0].Line != 2 || outline.H1[1].Line != 6 {
+		t.Errorf("H1 = %+v, want lines 2 and 6", outline.H1)
+	}
+	if len(outline.MissingAlt) != 2 || outline.MissingAlt[0] != (PageElement{Line: 3, Text: "/a.png"}) ||
+		outline.MissingAlt[1] != (PageElement{Line: 6, Text: "/d.png"}) {
+		t.Errorf("MissingAlt = %+v, want /a.png on line 3 and /d.png on line 6", outline.MissingAlt)
+	}
+}
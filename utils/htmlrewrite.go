@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// urlAttributes 是值为单个 URL 的 HTML 属性
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"poster":     true,
+	"action":     true,
+	"formaction": true,
+	"cite":       true,
+	"data":       true,
+	"xlink:href": true,
+}
+
+// URLRewriter 返回替换后的 URL，不需要替换时第二个返回值为 false
+type URLRewriter func(url string) (string, bool)
+
+// TagAttributeRewriter 返回属性的新值，不需要替换时第二个返回值为 false
+type TagAttributeRewriter func(tag, name, value string) (string, bool)
+
+// RewriteHTMLURLs 替换 HTML 中 href、src、srcset 等属性、style 属性和 <style> 中的 URL。
+// 只修改需要替换的属性值，其余内容按原样保留；属性值中的字符实体不会被解码
+func RewriteHTMLURLs(data []byte, rewrite URLRewriter) []byte {
+	inStyle := false
+	return RewriteHTMLTokens(data, func(tt html.TokenType, tag string, raw []byte) []byte {
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			inStyle = tag == "style" && tt == html.StartTagToken
+			return RewriteTagAttributes(raw, func(tag, name, value string) (string, bool) {
+				switch {
+				case urlAttributes[name]:
+					return rewrite(value)
+				case name == "srcset":
+					return rewriteSrcset(value, rewrite)
+				case name == "style":
+					css := RewriteCSSURLs([]byte(value), rewrite)
+					return string(css), string(css) != value
+				}
+				return "", false
+			})
+		case html.EndTagToken:
+			inStyle = false
+		case html.TextToken:
+			if inStyle {
+				return RewriteCSSURLs(raw, rewrite)
+			}
+		}
+		return raw
+	})
+}
+
+// RewriteHTMLTokens 依次把每个 token 的原始内容交给 fn，输出 fn 返回的内容；
+// fn 原样返回时输出与输入完全相同
+func RewriteHTMLTokens(data []byte, fn func(tt html.TokenType, tag string, raw []byte) []byte) []byte {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// 读到结尾，未能识别的剩余内容原样输出
+			out.Write(z.Raw())
+			break
+		}
+		raw := append([]byte(nil), z.Raw()...)
+		tag := ""
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken || tt == html.EndTagToken {
+			name, _ := z.TagName()
+			tag = string(name)
+		}
+		out.Write(fn(tt, tag, raw))
+	}
+	return out.Bytes()
+}
+
+// htmlAttribute 是原始标签中的一个属性及其值的位置
+type htmlAttribute struct {
+	Name       string
+	Value      string
+	ValueStart int // 值在标签中的起止位置（不含引号），没有值时为 -1
+	ValueEnd   int
+	Quote      byte
+}
+
+// parseTagAttributes 解析原始开始标签 <tag a="1" b='2' c=3 d> 中的属性
+func parseTagAttributes(raw []byte) (string, []htmlAttribute) {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+
+	i := 1
+	for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' && raw[i] != '/' {
+		i++
+	}
+	tag := strings.ToLower(string(raw[1:i]))
+
+	var attrs []htmlAttribute
+	for i < len(raw) {
+		for i < len(raw) && (isSpace(raw[i]) || raw[i] == '/') {
+			i++
+		}
+		if i >= len(raw) || raw[i] == '>' {
+			break
+		}
+
+		nameStart := i
+		for i < len(raw) && !isSpace(raw[i]) && raw[i] != '=' && raw[i] != '>' && !(raw[i] == '/' && i+1 < len(raw) && raw[i+1] == '>') {
+			i++
+		}
+		attr := htmlAttribute{Name: strings.ToLower(string(raw[nameStart:i])), ValueStart: -1, ValueEnd: -1}
+
+		j := i
+		for j < len(raw) && isSpace(raw[j]) {
+			j++
+		}
+		if j < len(raw) && raw[j] == '=' {
+			i = j + 1
+			for i < len(raw) && isSpace(raw[i]) {
+				i++
+			}
+			if i < len(raw) && (raw[i] == '"' || raw[i] == '\'') {
+				attr.Quote = raw[i]
+				end := bytes.IndexByte(raw[i+1:], attr.Quote)
+				if end < 0 {
+					end = len(raw) - i - 1
+				}
+				attr.ValueStart, attr.ValueEnd = i+1, i+1+end
+				i = attr.ValueEnd + 1
+			} else {
+				attr.ValueStart = i
+				for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' {
+					i++
+				}
+				attr.ValueEnd = i
+			}
+			attr.Value = string(raw[attr.ValueStart:attr.ValueEnd])
+		}
+		attrs = append(attrs, attr)
+	}
+	return tag, attrs
+}
+
+// RewriteTagAttributes 替换原始开始标签中的属性值，其余内容保持不变
+func RewriteTagAttributes(raw []byte, rewrite TagAttributeRewriter) []byte {
+	tag, attrs := parseTagAttributes(raw)
+
+	var out []byte
+	last := 0
+	for _, attr := range attrs {
+		if attr.ValueStart < 0 {
+			continue
+		}
+		value, ok := rewrite(tag, attr.Name, attr.Value)
+		if !ok || value == attr.Value {
+			continue
+		}
+		out = append(out, raw[last:attr.ValueStart]...)
+		if attr.Quote == 0 && strings.ContainsAny(value, " \t\n\"'=<>`") {
+			out = append(out, '"')
+			out = append(out, strings.ReplaceAll(value, `"`, "&quot;")...)
+			out = append(out, '"')
+		} else {
+			out = append(out, value...)
+		}
+		last = attr.ValueEnd
+	}
+	if out == nil {
+		return raw
+	}
+	return append(out, raw[last:]...)
+}
+
+// rewriteSrcset 替换 srcset 中每个候选图片的 URL
+func rewriteSrcset(value string, rewrite URLRewriter) (string, bool) {
+	candidates := strings.Split(value, ",")
+	changed := false
+	for i, candidate := range candidates {
+		trimmed := strings.TrimLeft(candidate, " \t\n")
+		leading := candidate[:len(candidate)-len(trimmed)]
+		url, descriptor := trimmed, ""
+		if space := strings.IndexAny(trimmed, " \t\n"); space >= 0 {
+			url, descriptor = trimmed[:space], trimmed[space:]
+		}
+		if newURL, ok := rewrite(url); ok {
+			candidates[i] = leading + newURL + descriptor
+			changed = true
+		}
+	}
+	return strings.Join(candidates, ","), changed
+}
+
+// TagAttributes 返回原始开始标签中的属性（字符实体已解码），同名属性只保留第一个
+func TagAttributes(raw []byte) map[string]string {
+	_, attrs := parseTagAttributes(raw)
+	values := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		if _, ok := values[attr.Name]; !ok {
+			values[attr.Name] = html.UnescapeString(attr.Value)
+		}
+	}
+	return values
+}
+
+// AddTagAttribute 在原始开始标签的结尾添加属性，其余内容保持不变
+func AddTagAttribute(raw []byte, name, value string) []byte {
+	end := len(raw) - 1
+	if end > 0 && raw[end-1] == '/' {
+		end--
+	}
+	attr := fmt.Sprintf(` %s="%s"`, name, html.EscapeString(value))
+	if end > 0 && (raw[end-1] == ' ' || raw[end-1] == '\t' || raw[end-1] == '\n') {
+		attr = attr[1:] + " "
+	}
+	out := append([]byte(nil), raw[:end]...)
+	out = append(out, attr...)
+	return append(out, raw[end:]...)
+}
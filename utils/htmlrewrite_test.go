@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// prefixRoot 为根相对路径添加 /blog 前缀
+func prefixRoot(url string) (string, bool) {
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") {
+		return "/blog" + url, true
+	}
+	return "", false
+}
+
+func TestRewriteHTMLURLs(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><head>
+<link rel="stylesheet" href="/css/main.css">
+<style>body { background: url('/img/bg.png'); }</style>
+<script>var path = "/not/rewritten";</script>
+</head>
+<body>
+<a href=/posts/ class=nav>Posts</a>
+<a HREF='https://example.com/'>External</a>
+<img src="/img/a.png" srcset="/img/a.png 1x, /img/a@2x.png 2x" alt="/kept">
+<div style="background-image: url(/img/c.png)"></div>
+<a href="#top">Top</a><br/>
+</body></html>`
+
+	want := `<!DOCTYPE html>
+<html><head>
+<link rel="stylesheet" href="/blog/css/main.css">
+<style>body { background: url('/blog/img/bg.png'); }</style>
+<script>var path = "/not/rewritten";</script>
+</head>
+<body>
+<a href=/blog/posts/ class=nav>Posts</a>
+<a HREF='https://example.com/'>External</a>
+<img src="/blog/img/a.png" srcset="/blog/img/a.png 1x, /blog/img/a@2x.png 2x" alt="/kept">
+<div style="background-image: url(/blog/img/c.png)"></div>
+<a href="#top">Top</a><br/>
+</body></html>`
+
+	if got := string(RewriteHTMLURLs([]byte(input), prefixRoot)); got != want {
+		t.Errorf("RewriteHTMLURLs() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRewriteCSSURLs(t *testing.T) {
+	input := `@import "/css/base.css";
+@import url(/css/print.css) print;
+.a { background: url("/img/a.png") no-repeat; }
+.b { background: url(data:image/png;base64,AAAA); }
+.c { src: url( '/fonts/x.woff2' ) format("woff2"); }`
+
+	want := `@import "/blog/css/base.css";
+@import url(/blog/css/print.css) print;
+.a { background: url("/blog/img/a.png") no-repeat; }
+.b { background: url(data:image/png;base64,AAAA); }
+.c { src: url( '/blog/fonts/x.woff2' ) format("woff2"); }`
+
+	if got := string(RewriteCSSURLs([]byte(input), prefixRoot)); got != want {
+		t.Errorf("RewriteCSSURLs() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddTagAttribute(t *testing.T) {
+	tests := map[string]string{
+		`<script src="/a.js">`:               `<script src="/a.js" integrity="sha384-x">`,
+		`<link rel=stylesheet href=a.css />`: `<link rel=stylesheet href=a.css integrity="sha384-x" />`,
+		`<link href="a.css"/>`:               `<link href="a.css" integrity="sha384-x"/>`,
+	}
+	for input, want := range tests {
+		if got := string(AddTagAttribute([]byte(input), "integrity", "sha384-x")); got != want {
+			t.Errorf("AddTagAttribute(%s) = %s, want %s", input, got, want)
+		}
+	}
+
+	attrs := TagAttributes([]byte(`<a HREF="/x?a=1&amp;b=2" onclick='go()' hidden>`))
+	if attrs["href"] != "/x?a=1&b=2" || attrs["onclick"] != "go()" {
+		t.Errorf("TagAttributes() = %v", attrs)
+	}
+	if _, ok := attrs["hidden"]; !ok {
+		t.Errorf("TagAttributes() = %v, want hidden", attrs)
+	}
+}
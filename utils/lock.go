@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockInfo 记录持有锁的进程
+type LockInfo struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+// LockedError 表示锁已被其他进程持有
+type LockedError struct {
+	Info *LockInfo
+}
+
+func (e *LockedError) Error() string {
+	if e.Info == nil || e.Info.PID == 0 {
+		return "project is locked by another wordma command"
+	}
+	return fmt.Sprintf("project is locked by '%s' (PID %d, started %s)",
+		e.Info.Command, e.Info.PID, e.Info.Started.Format("2006-01-02 15:04:05"))
+}
+
+// FileLock 是基于操作系统文件锁（flock / LockFileEx）的咨询锁。
+// 进程退出时操作系统自动释放锁，因此异常退出不会留下真正的死锁，
+// 锁文件中残留的进程信息只用于提示
+type FileLock struct {
+	file *os.File
+	// Stale 是上一个持有者异常退出时留下的信息，没有时为 nil
+	Stale *LockInfo
+}
+
+// TryLock 尝试获取锁，锁被其他进程持有时返回 *LockedError
+func TryLock(path, command string) (*FileLock, error) {
+	if err := CreateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, &LockedError{Info: readLockInfo(path)}
+		}
+		return nil, err
+	}
+
+	// 拿到锁时文件中仍有内容，说明上一个持有者没有正常释放
+	lock := &FileLock{file: file, Stale: readLockInfoFrom(file)}
+
+	info := LockInfo{PID: os.Getpid(), Command: command, Started: time.Now()}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err == nil {
+		err = lock.write(data)
+	}
+	if err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// AcquireLock 获取锁；wait 为 true 时等待其他进程释放，onWait 在开始等待时调用一次
+func AcquireLock(path, command string, wait bool, onWait func(*LockedError)) (*FileLock, error) {
+	notified := false
+	for {
+		lock, err := TryLock(path, command)
+		var locked *LockedError
+		if err == nil || !wait || !errors.As(err, &locked) {
+			return lock, err
+		}
+		if !notified && onWait != nil {
+			onWait(locked)
+			notified = true
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// Release 清空锁文件中的进程信息并释放锁
+func (l *FileLock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	unlockFile(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// write 用 data 替换锁文件内容
+func (l *FileLock) write(data []byte) error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := l.file.WriteAt(data, 0); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// readLockInfo 读取锁文件中的持有者信息，读取失败时返回 nil
+func readLockInfo(path string) *LockInfo {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	return readLockInfoFrom(file)
+}
+
+func readLockInfoFrom(file *os.File) *LockInfo {
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, 1<<20))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var info LockInfo
+	if json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+	return &info
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryLockContention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	first, err := TryLock(path, "wordma build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = TryLock(path, "wordma update")
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	if locked.Info == nil || locked.Info.PID != os.Getpid() || locked.Info.Command != "wordma build" {
+		t.Errorf("unexpected lock holder: %+v", locked.Info)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := TryLock(path, "wordma update")
+	if err != nil {
+		t.Fatalf("expected lock to be free after release, got %v", err)
+	}
+	defer second.Release()
+	if second.Stale != nil {
+		t.Errorf("expected no stale info after a clean release, got %+v", second.Stale)
+	}
+}
+
+func TestTryLockStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	// 模拟持有者退出时没有清空锁文件
+	data, _ := json.Marshal(LockInfo{PID: 12345, Command: "wordma build", Started: time.Now()})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := TryLock(path, "wordma deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+	if lock.Stale == nil || lock.Stale.PID != 12345 || lock.Stale.Command != "wordma build" {
+		t.Errorf("expected stale info from previous holder, got %+v", lock.Stale)
+	}
+}
+
+func TestAcquireLockWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	first, err := TryLock(path, "wordma build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		first.Release()
+	}()
+
+	waited := false
+	lock, err := AcquireLock(path, "wordma update", true, func(*LockedError) { waited = true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+	if !waited {
+		t.Error("expected onWait to be called while the lock was held")
+	}
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// PrefixWriter 为每一行输出添加前缀，多个共享同一把锁的 PrefixWriter 输出的行不会互相穿插
+type PrefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+// NewPrefixWriter 创建一个按行添加前缀的输出，mu 用于在多个输出之间同步
+func NewPrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{mu: mu, w: w, prefix: prefix}
+}
+
+// Write 缓存不完整的行，遇到换行时整行输出
+func (p *PrefixWriter) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush 输出缓存中剩余的不完整行
+func (p *PrefixWriter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+	return err
+}
+
+func (p *PrefixWriter) writeLine(line []byte) error {
+	if _, err := io.WriteString(p.w, p.prefix); err != nil {
+		return err
+	}
+	_, err := p.w.Write(line)
+	return err
+}
+
+// secretMask 是替换密钥后显示的内容
+const secretMask = "********"
+
+// MaskingWriter 将输出中出现的密钥替换为 ********。
+// 末尾可能是某个密钥开头的部分会暂存，等后续数据到达后再判断
+type MaskingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	buf     []byte
+}
+
+// NewMaskingWriter 创建替换密钥的输出，过短的值（少于 4 个字符）不会被替换
+func NewMaskingWriter(w io.Writer, secrets []string) *MaskingWriter {
+	m := &MaskingWriter{w: w}
+	for _, secret := range secrets {
+		if len(secret) >= 4 {
+			m.secrets = append(m.secrets, []byte(secret))
+		}
+	}
+	// 先替换较长的密钥，避免其中包含的较短密钥被单独替换
+	sort.Slice(m.secrets, func(i, j int) bool { return len(m.secrets[i]) > len(m.secrets[j]) })
+	return m
+}
+
+// Write 替换密钥后输出
+func (m *MaskingWriter) Write(data []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.buf = append(m.buf, data...)
+	out, rest := m.mask(m.buf, false)
+	if _, err := m.w.Write(out); err != nil {
+		return 0, err
+	}
+	m.buf = append([]byte(nil), rest...)
+	return len(data), nil
+}
+
+// Flush 输出暂存的剩余内容
+func (m *MaskingWriter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out, _ := m.mask(m.buf, true)
+	_, err := m.w.Write(out)
+	m.buf = nil
+	return err
+}
+
+// mask 从左到右替换 data 中的密钥；final 为 false 时，从可能是某个密钥开头的
+// 不完整内容处停止，返回已处理的输出和剩余未处理的部分
+func (m *MaskingWriter) mask(data []byte, final bool) ([]byte, []byte) {
+	var out []byte
+	i := 0
+scan:
+	for i < len(data) {
+		rest := data[i:]
+		if !final {
+			for _, secret := range m.secrets {
+				if len(rest) < len(secret) && bytes.HasPrefix(secret, rest) {
+					break scan
+				}
+			}
+		}
+		for _, secret := range m.secrets {
+			if bytes.HasPrefix(rest, secret) {
+				out = append(out, secretMask...)
+				i += len(secret)
+				continue scan
+			}
+		}
+		out = append(out, data[i])
+		i++
+	}
+	return out, data[i:]
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMaskingWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewMaskingWriter(&out, []string{"s3cr3t-token", "abc", "s3cr3t"})
+
+	// 密钥被拆分到多次写入中
+	for _, chunk := range []string{"token=s3c", "r3t-tok", "en ok\n", "other s3cr3t", " abc"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "token=******** ok\nother ******** abc"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
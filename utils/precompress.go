@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// PrecompressOptions 是预压缩的设置
+type PrecompressOptions struct {
+	// MinSize 小于该大小的文件不压缩
+	MinSize int64
+	// Extensions 需要压缩的文件扩展名（如 ".html"）
+	Extensions []string
+	// Brotli 是否同时使用 brotli 命令生成 .br 文件
+	Brotli bool
+	// Jobs 并行压缩的文件数，不大于 0 时使用 CPU 数量
+	Jobs int
+	// Previous 上一次的输出目录，内容相同的文件直接复用其中已有的压缩文件
+	Previous string
+}
+
+// PrecompressResult 是一次预压缩的统计
+type PrecompressResult struct {
+	Written  int   // 新生成的压缩文件数
+	UpToDate int   // 已是最新或从上一次输出复用的压缩文件数
+	Saved    int64 // 压缩文件比原文件节省的字节数（按 gzip 计算）
+}
+
+// precompressFormat 是一种预压缩格式
+type precompressFormat struct {
+	ext      string
+	compress func(src, dst string) error
+}
+
+// PrecompressedExtensions 是预压缩生成的文件扩展名
+var PrecompressedExtensions = []string{".gz", ".br"}
+
+// IsPrecompressedFile 判断 path 是否是同目录下另一个文件的预压缩版本
+func IsPrecompressedFile(path string) bool {
+	for _, ext := range PrecompressedExtensions {
+		if strings.HasSuffix(path, ext) && FileExists(strings.TrimSuffix(path, ext)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Precompress 为 dir 中符合条件的文件在旁边生成 .gz（以及 .br），
+// 压缩文件比原文件新时跳过，压缩后没有变小的文件不保留压缩版本
+func Precompress(dir string, opts PrecompressOptions) (*PrecompressResult, error) {
+	formats := []precompressFormat{{ext: ".gz", compress: gzipFile}}
+	if opts.Brotli {
+		formats = append(formats, precompressFormat{ext: ".br", compress: brotliFile})
+	}
+
+	extensions := make(map[string]bool)
+	for _, ext := range opts.Extensions {
+		extensions[strings.ToLower(ext)] = true
+	}
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && info.Size() >= opts.MinSize && extensions[strings.ToLower(filepath.Ext(path))] {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		result   = &PrecompressResult{}
+		paths    = make(chan string)
+	)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				fileResult, err := precompressFile(dir, path, formats, opts.Previous)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				result.Written += fileResult.Written
+				result.UpToDate += fileResult.UpToDate
+				result.Saved += fileResult.Saved
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range files {
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+
+	return result, firstErr
+}
+
+// precompressFile 生成一个文件的全部压缩版本
+func precompressFile(dir, path string, formats []precompressFormat, previous string) (PrecompressResult, error) {
+	var result PrecompressResult
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return result, err
+	}
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return result, err
+	}
+
+	for _, format := range formats {
+		dst := path + format.ext
+		if isUpToDate(dst, info) {
+			result.UpToDate++
+		} else {
+			reused, err := reusePrecompressed(path, filepath.Join(previous, relPath), format.ext, previous != "")
+			if err != nil {
+				return result, err
+			}
+			if !reused {
+				if err := format.compress(path, dst); err != nil {
+					os.Remove(dst)
+					return result, fmt.Errorf("failed to compress %s: %v", relPath, err)
+				}
+			}
+
+			compressed, err := os.Stat(dst)
+			if err != nil {
+				return result, err
+			}
+			// 压缩后没有变小的文件直接提供原文件
+			if compressed.Size() >= info.Size() {
+				os.Remove(dst)
+				continue
+			}
+			// 压缩文件的修改时间与原文件相同，据此判断是否需要重新压缩
+			if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+				return result, err
+			}
+			if reused {
+				result.UpToDate++
+			} else {
+				result.Written++
+			}
+		}
+
+		if format.ext == ".gz" {
+			if compressed, err := os.Stat(dst); err == nil {
+				result.Saved += info.Size() - compressed.Size()
+			}
+		}
+	}
+	return result, nil
+}
+
+// isUpToDate 判断压缩文件存在且不比原文件旧
+func isUpToDate(dst string, src os.FileInfo) bool {
+	info, err := os.Stat(dst)
+	return err == nil && !info.ModTime().Before(src.ModTime())
+}
+
+// reusePrecompressed 上一次输出中的同一文件内容相同且有最新的压缩版本时复制过来
+func reusePrecompressed(path, previousPath, ext string, enabled bool) (bool, error) {
+	if !enabled {
+		return false, nil
+	}
+	previousInfo, err := os.Stat(previousPath)
+	if err != nil || !isUpToDate(previousPath+ext, previousInfo) {
+		return false, nil
+	}
+
+	hash, err := HashFile(path)
+	if err != nil {
+		return false, err
+	}
+	previousHash, err := HashFile(previousPath)
+	if err != nil || hash != previousHash {
+		return false, nil
+	}
+	if err := CopyFile(previousPath+ext, path+ext); err != nil {
+		os.Remove(path + ext)
+		return false, nil
+	}
+	return true, nil
+}
+
+// gzipFile 以最高级别 gzip 压缩文件
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// brotliFile 使用 brotli 命令以最高质量压缩文件
+func brotliFile(src, dst string) error {
+	output, err := NewCommand("brotli", "--force", "--quality=11", "--output="+dst, src).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
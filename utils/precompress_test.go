@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrecompress(t *testing.T) {
+	dir := t.TempDir()
+	page := strings.Repeat("<p>hello precompress</p>\n", 200)
+	files := map[string]string{
+		"index.html":   page,
+		"small.css":    "a{}",
+		"image.png":    page,
+		"js/app.js":    page,
+		"data.json.gz": page,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := PrecompressOptions{MinSize: 1024, Extensions: []string{".html", ".css", ".js"}, Jobs: 2}
+	result, err := Precompress(dir, opts)
+	if err != nil {
+		t.Fatalf("Precompress() error = %v", err)
+	}
+	if result.Written != 2 || result.UpToDate != 0 {
+		t.Errorf("first run: Written = %d, UpToDate = %d, want 2, 0", result.Written, result.UpToDate)
+	}
+	for _, name := range []string{"small.css.gz", "image.png.gz"} {
+		if FileExists(filepath.Join(dir, name)) {
+			t.Errorf("%s should not be created", name)
+		}
+	}
+
+	file, err := os.Open(filepath.Join(dir, "index.html.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != page {
+		t.Errorf("index.html.gz does not decompress to the original content")
+	}
+
+	result, err = Precompress(dir, opts)
+	if err != nil {
+		t.Fatalf("Precompress() error = %v", err)
+	}
+	if result.Written != 0 || result.UpToDate != 2 {
+		t.Errorf("second run: Written = %d, UpToDate = %d, want 0, 2", result.Written, result.UpToDate)
+	}
+
+	if !IsPrecompressedFile(filepath.Join(dir, "index.html.gz")) {
+		t.Errorf("IsPrecompressedFile(index.html.gz) = false, want true")
+	}
+	if IsPrecompressedFile(filepath.Join(dir, "data.json.gz")) {
+		t.Errorf("IsPrecompressedFile(data.json.gz) = true, want false")
+	}
+}
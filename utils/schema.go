@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaError 描述一个不符合 JSON Schema 的配置项
+type SchemaError struct {
+	Path    []string
+	Message string
+}
+
+// Key 返回出错配置项的点分隔键名
+func (e SchemaError) Key() string {
+	if len(e.Path) == 0 {
+		return "(root)"
+	}
+	return strings.Join(e.Path, ".")
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key(), e.Message)
+}
+
+// Schema 是已加载的 JSON Schema（支持常用的 draft-07 关键字子集）
+type Schema struct {
+	root map[string]interface{}
+}
+
+// LoadSchema 从文件加载 JSON Schema
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %v", path, err)
+	}
+	return &Schema{root: root}, nil
+}
+
+// Validate 校验配置，返回全部错误
+func (s *Schema) Validate(value interface{}) []SchemaError {
+	var errs []SchemaError
+	s.validate(s.root, value, nil, &errs)
+	return errs
+}
+
+// resolve 解析本地 $ref（#/definitions/... 或 #/$defs/...）
+func (s *Schema) resolve(schema map[string]interface{}) map[string]interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return schema
+		}
+
+		var current interface{} = s.root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+			if part == "" {
+				continue
+			}
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			node, ok := current.(map[string]interface{})
+			if !ok {
+				return schema
+			}
+			current = node[part]
+		}
+
+		resolved, ok := current.(map[string]interface{})
+		if !ok {
+			return schema
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+func appendPath(path []string, key string) []string {
+	result := make([]string, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, key)
+}
+
+func (s *Schema) validate(schema map[string]interface{}, value interface{}, path []string, errs *[]SchemaError) {
+	schema = s.resolve(schema)
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// type
+	if expected, ok := schema["type"]; ok {
+		var types []string
+		switch t := expected.(type) {
+		case string:
+			types = []string{t}
+		case []interface{}:
+			for _, item := range t {
+				if name, ok := item.(string); ok {
+					types = append(types, name)
+				}
+			}
+		}
+		if len(types) > 0 && !matchesAnyType(value, types) {
+			fail("expected %s, got %s", strings.Join(types, " or "), schemaTypeOf(value))
+			return
+		}
+	}
+
+	// enum / const
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", formatSchemaValues(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		fail("must be %s", formatSchemaValues([]interface{}{constant}))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if min, ok := schema["minLength"].(float64); ok && float64(length) < min {
+			fail("must be at least %d characters long", int(min))
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(length) > max {
+			fail("must be at most %d characters long", int(max))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("must match pattern %q", pattern)
+			}
+		}
+
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && v < min {
+			fail("must be >= %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && v > max {
+			fail("must be <= %v", max)
+		}
+		if min, ok := schema["exclusiveMinimum"].(float64); ok && v <= min {
+			fail("must be > %v", min)
+		}
+		if max, ok := schema["exclusiveMaximum"].(float64); ok && v >= max {
+			fail("must be < %v", max)
+		}
+
+	case []interface{}:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+			fail("must contain at least %d items", int(min))
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+			fail("must contain at most %d items", int(max))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				s.validate(items, item, appendPath(path, fmt.Sprint(i)), errs)
+			}
+		}
+
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, item := range required {
+				if name, ok := item.(string); ok {
+					if _, exists := v[name]; !exists {
+						*errs = append(*errs, SchemaError{Path: appendPath(path, name), Message: "is required"})
+					}
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				s.validate(propSchema, v[key], appendPath(path, key), errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					*errs = append(*errs, SchemaError{Path: appendPath(path, key), Message: "is not an allowed property"})
+				}
+			case map[string]interface{}:
+				s.validate(additional, v[key], appendPath(path, key), errs)
+			}
+		}
+	}
+
+	// 组合关键字
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, item := range allOf {
+			if sub, ok := item.(map[string]interface{}); ok {
+				s.validate(sub, value, path, errs)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && s.countMatches(anyOf, value) == 0 {
+		fail("does not match any of the allowed schemas")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		switch matched := s.countMatches(oneOf, value); matched {
+		case 1:
+		case 0:
+			fail("does not match any of the allowed schemas")
+		default:
+			fail("matches %d of the allowed schemas, expected exactly one", matched)
+		}
+	}
+}
+
+// countMatches 返回值满足的子 schema 个数
+func (s *Schema) countMatches(schemas []interface{}, value interface{}) int {
+	matched := 0
+	for _, item := range schemas {
+		sub, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var errs []SchemaError
+		s.validate(sub, value, nil, &errs)
+		if len(errs) == 0 {
+			matched++
+		}
+	}
+	return matched
+}
+
+// schemaTypeOf 返回值对应的 JSON Schema 类型名
+func schemaTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := schemaTypeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b interface{}) bool {
+	left, err1 := json.Marshal(a)
+	right, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(left) == string(right)
+}
+
+func formatSchemaValues(values []interface{}) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		encoded, _ := json.Marshal(value)
+		parts = append(parts, string(encoded))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["title"],
+  "additionalProperties": false,
+  "properties": {
+    "title": {"type": "string", "minLength": 2, "maxLength": 10},
+    "theme": {"enum": ["light", "dark"]},
+    "perPage": {"type": "integer", "minimum": 1, "maximum": 50},
+    "ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+    "tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+    "author": {"$ref": "#/definitions/author"},
+    "logo": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+    "id": {"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 100}]},
+    "slug": {"allOf": [{"type": "string"}, {"pattern": "^[a-z-]+$"}]},
+    "extra": {"type": "object", "additionalProperties": {"type": "boolean"}}
+  },
+  "definitions": {
+    "author": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string"}}
+    }
+  }
+}`
+
+func validateTestSchema(t *testing.T, config string) []string {
+	t.Helper()
+	schema := &Schema{}
+	if err := json.Unmarshal([]byte(testSchema), &schema.root); err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(config), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, err := range schema.Validate(value) {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			config: `{"title": "Blog", "theme": "dark", "perPage": 10, "ratio": 0.5, "tags": ["a"], "author": {"name": "A"}, "logo": null, "id": 5, "slug": "my-blog", "extra": {"x": true}}`,
+		},
+		{name: "type", config: `[]`, want: []string{"(root): expected object, got array"}},
+		{name: "required", config: `{}`, want: []string{"title: is required"}},
+		{name: "additionalProperties false", config: `{"title": "Blog", "foo": 1}`, want: []string{"foo: is not an allowed property"}},
+		{name: "additionalProperties schema", config: `{"title": "Blog", "extra": {"x": 1}}`, want: []string{"extra.x: expected boolean, got integer"}},
+		{name: "enum", config: `{"title": "Blog", "theme": "blue"}`, want: []string{`theme: must be one of "light", "dark"`}},
+		{name: "integer", config: `{"title": "Blog", "perPage": 1.5}`, want: []string{"perPage: expected integer, got number"}},
+		{name: "minLength", config: `{"title": "B"}`, want: []string{"title: must be at least 2 characters long"}},
+		{name: "maxLength", config: `{"title": "A very long title"}`, want: []string{"title: must be at most 10 characters long"}},
+		{name: "minimum", config: `{"title": "Blog", "perPage": 0}`, want: []string{"perPage: must be >= 1"}},
+		{name: "maximum", config: `{"title": "Blog", "perPage": 51}`, want: []string{"perPage: must be <= 50"}},
+		{name: "exclusiveMinimum", config: `{"title": "Blog", "ratio": 0}`, want: []string{"ratio: must be > 0"}},
+		{name: "exclusiveMaximum", config: `{"title": "Blog", "ratio": 1}`, want: []string{"ratio: must be < 1"}},
+		{name: "minItems", config: `{"title": "Blog", "tags": []}`, want: []string{"tags: must contain at least 1 items"}},
+		{name: "maxItems", config: `{"title": "Blog", "tags": ["a", "b", "c"]}`, want: []string{"tags: must contain at most 2 items"}},
+		{name: "items", config: `{"title": "Blog", "tags": [1]}`, want: []string{"tags.0: expected string, got integer"}},
+		{name: "$ref", config: `{"title": "Blog", "author": {}}`, want: []string{"author.name: is required"}},
+		{name: "anyOf", config: `{"title": "Blog", "logo": 1}`, want: []string{"logo: does not match any of the allowed schemas"}},
+		{name: "oneOf none", config: `{"title": "Blog", "id": "x"}`, want: []string{"id: does not match any of the allowed schemas"}},
+		{name: "oneOf several", config: `{"title": "Blog", "id": 200}`, want: []string{"id: matches 2 of the allowed schemas, expected exactly one"}},
+		{name: "allOf", config: `{"title": "Blog", "slug": "My Blog"}`, want: []string{`slug: must match pattern "^[a-z-]+$"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateTestSchema(t, tt.config)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SearchDocument 是从页面中提取的可搜索内容
+type SearchDocument struct {
+	Title    string   `json:"title"`
+	Headings []string `json:"headings,omitempty"`
+	Text     string   `json:"text"`
+}
+
+// searchSkippedElements 是不包含正文内容的元素
+var searchSkippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Svg: true, atom.Iframe: true, atom.Form: true, atom.Button: true,
+}
+
+// searchInlineElements 是不会在文字之间产生换行的元素，其两侧的文字直接相连
+var searchInlineElements = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Bdi: true, atom.Bdo: true, atom.Cite: true,
+	atom.Code: true, atom.Data: true, atom.Dfn: true, atom.Em: true, atom.I: true, atom.Kbd: true,
+	atom.Mark: true, atom.Q: true, atom.Rp: true, atom.Rt: true, atom.Ruby: true, atom.S: true,
+	atom.Samp: true, atom.Small: true, atom.Span: true, atom.Strong: true, atom.Sub: true,
+	atom.Sup: true, atom.Time: true, atom.U: true, atom.Var: true, atom.Wbr: true, atom.Del: true, atom.Ins: true,
+}
+
+// ExtractSearchDocument 提取页面的标题、h1-h3 标题和正文文字，正文最多保留 maxText 个字符（不大于 0 时不限制）。
+// 正文优先取 <main>，其次是 <article> 和 <body>；导航、页眉页脚、脚本和带 data-search-ignore 的元素不包含在内
+func ExtractSearchDocument(data []byte, maxText int) (SearchDocument, error) {
+	var doc SearchDocument
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return doc, err
+	}
+
+	if title := findElement(root, atom.Title); title != nil {
+		doc.Title = CollapseText(nodeText(title))
+	}
+
+	content := findElement(root, atom.Main)
+	if content == nil {
+		content = findElement(root, atom.Article)
+	}
+	if content == nil {
+		content = findElement(root, atom.Body)
+	}
+	if content == nil {
+		return doc, nil
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if searchSkippedElements[c.DataAtom] || hasAttribute(c, "data-search-ignore") {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.H1, atom.H2, atom.H3:
+				if heading := CollapseText(nodeText(c)); heading != "" {
+					doc.Headings = append(doc.Headings, heading)
+				}
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(content)
+
+	doc.Text = CollapseText(nodeText(content))
+	if maxText > 0 && utf8.RuneCountInString(doc.Text) > maxText {
+		doc.Text = truncateRunes(doc.Text, maxText)
+	}
+	return doc, nil
+}
+
+// findElement 按文档顺序查找第一个指定标签的元素
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hasAttribute 判断元素是否有指定属性
+func hasAttribute(n *html.Node, key string) bool {
+	_, ok := attribute(n, key)
+	return ok
+}
+
+// blockBreak 标记块级元素的边界，CollapseText 总是将其替换为空格
+const blockBreak = '\u2029'
+
+// nodeText 返回元素中的文字：块级元素和 <br> 处插入 blockBreak，行内元素两侧的文字直接相连
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if searchSkippedElements[n.DataAtom] || hasAttribute(n, "data-search-ignore") {
+				return
+			}
+			if n.DataAtom == atom.Br {
+				sb.WriteRune(blockBreak)
+				return
+			}
+			if n.DataAtom == atom.Img {
+				if alt, ok := attribute(n, "alt"); ok {
+					sb.WriteString(alt)
+				}
+				return
+			}
+		}
+		block := n.Type == html.ElementNode && !searchInlineElements[n.DataAtom]
+		if block {
+			sb.WriteRune(blockBreak)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			sb.WriteRune(blockBreak)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// attribute 返回元素的属性值
+func attribute(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// CollapseText 将连续的空白合并为一个空格；两侧都是中文或日文时去掉空白，
+// 因为源码中的换行在这些文字之间不表示空格（韩文使用空格分词，保留）。
+// 段落分隔符 U+2029 总是保留为空格
+func CollapseText(s string) string {
+	var sb strings.Builder
+	var last rune
+	pendingSpace, hardSpace := false, false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			pendingSpace = sb.Len() > 0
+			hardSpace = hardSpace || r == blockBreak
+			continue
+		}
+		if pendingSpace && (hardSpace || !(isCJK(last) && isCJK(r))) {
+			sb.WriteByte(' ')
+		}
+		pendingSpace, hardSpace = false, false
+		sb.WriteRune(r)
+		last = r
+	}
+	return sb.String()
+}
+
+// isCJK 判断字符是否是不用空格分词的中日文字或全角标点
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// truncateRunes 截取前 n 个字符
+func truncateRunes(s string, n int) string {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
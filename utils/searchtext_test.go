@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractSearchDocument(t *testing.T) {
+	page := `<html><head><title>你好 | 博客</title><script>var x = 1;</script></head>
+<body>
+<header><nav><a href="/">首页</a></nav></header>
+<main>
+  <h1>静态<em>博客</em>生成</h1>
+  <p>这是一段
+  中文文字，包含<strong>强调</strong>和 English words。</p>
+  <h2>Second  heading</h2>
+  <p>Line one<br>line two</p>
+  <div data-search-ignore>广告</div>
+  <img src="/a.png" alt="示意图">
+</main>
+<footer>版权所有</footer>
+</body></html>`
+
+	doc, err := ExtractSearchDocument([]byte(page), 0)
+	if err != nil {
+		t.Fatalf("ExtractSearchDocument() error = %v", err)
+	}
+	if doc.Title != "你好 | 博客" {
+		t.Errorf("Title = %q", doc.Title)
+	}
+	if strings.Join(doc.Headings, "|") != "静态博客生成|Second heading" {
+		t.Errorf("Headings = %q", doc.Headings)
+	}
+	want := "静态博客生成 这是一段中文文字，包含强调和 English words。 Second heading Line one line two 示意图"
+	if doc.Text != want {
+		t.Errorf("Text = %q, want %q", doc.Text, want)
+	}
+
+	doc, _ = ExtractSearchDocument([]byte(page), 4)
+	if doc.Text != "静态博客" {
+		t.Errorf("truncated Text = %q, want %q", doc.Text, "静态博客")
+	}
+}
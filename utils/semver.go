@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version 是解析后的语义化版本号（忽略预发布和构建元数据）
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Compare 比较两个版本：小于返回 -1，等于返回 0，大于返回 1
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// ParseVersion 解析 "v1.2.3"、"1.2"、"1.2.3-beta.1" 等格式的版本号
+func ParseVersion(version string) (Version, error) {
+	parts, _, err := parseVersionParts(version)
+	if err != nil {
+		return Version{}, err
+	}
+	return Version{parts[0], parts[1], parts[2]}, nil
+}
+
+// parseVersionParts 解析版本号，返回三段数字以及实际给出的段数（x/*/缺省的段不计入）
+func parseVersionParts(version string) ([3]int, int, error) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "="), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" || version == "*" || version == "x" || version == "X" {
+		return parts, 0, nil
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return parts, 0, fmt.Errorf("invalid version %q", version)
+	}
+
+	given := 0
+	for i, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return parts, 0, fmt.Errorf("invalid version %q", version)
+		}
+		parts[i] = number
+		given++
+	}
+	return parts, given, nil
+}
+
+// versionComparator 是版本范围中的一个比较条件，如 ">=1.2.0"
+type versionComparator struct {
+	op      string
+	version Version
+}
+
+func (c versionComparator) matches(v Version) bool {
+	result := v.Compare(c.version)
+	switch c.op {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return result == 0
+}
+
+// SatisfiesVersionRange 判断版本是否满足 npm 风格的版本范围，
+// 支持 ^、~、比较运算符、x 通配、"a - b" 区间以及 || 组合
+func SatisfiesVersionRange(version, versionRange string) (bool, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	for _, alternative := range strings.Split(versionRange, "||") {
+		comparators, err := parseVersionRange(strings.TrimSpace(alternative))
+		if err != nil {
+			return false, err
+		}
+
+		matched := true
+		for _, comparator := range comparators {
+			if !comparator.matches(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersionRange 将不含 || 的范围解析为需要同时满足的比较条件
+func parseVersionRange(versionRange string) ([]versionComparator, error) {
+	fields := strings.Fields(versionRange)
+
+	// 区间写法：1.2.3 - 2.3.4
+	if len(fields) == 3 && fields[1] == "-" {
+		lower, err := expandVersionTerm(">=" + fields[0])
+		if err != nil {
+			return nil, err
+		}
+		upper, err := expandVersionTerm("<=" + fields[2])
+		if err != nil {
+			return nil, err
+		}
+		return append(lower, upper...), nil
+	}
+
+	var comparators []versionComparator
+	for i := 0; i < len(fields); i++ {
+		term := fields[i]
+		// 允许运算符与版本号之间有空格，如 ">= 1.2"
+		if strings.Trim(term, "<>=^~") == "" && i+1 < len(fields) {
+			i++
+			term += fields[i]
+		}
+		expanded, err := expandVersionTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, expanded...)
+	}
+	return comparators, nil
+}
+
+// expandVersionTerm 将单个范围条件展开为基本比较条件
+func expandVersionTerm(term string) ([]versionComparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	parts, given, err := parseVersionParts(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, err
+	}
+	base := Version{parts[0], parts[1], parts[2]}
+
+	// 根据给出的段数计算通配时的上界，如 1.2 表示 >=1.2.0 <1.3.0
+	upperFor := func(given int) (Version, bool) {
+		switch given {
+		case 0:
+			return Version{}, false
+		case 1:
+			return Version{base.Major + 1, 0, 0}, true
+		case 2:
+			return Version{base.Major, base.Minor + 1, 0}, true
+		}
+		return Version{}, false
+	}
+
+	switch op {
+	case "^":
+		var upper Version
+		switch {
+		case base.Major > 0 || given <= 1:
+			upper = Version{base.Major + 1, 0, 0}
+		case base.Minor > 0 || given == 2:
+			upper = Version{0, base.Minor + 1, 0}
+		default:
+			upper = Version{0, 0, base.Patch + 1}
+		}
+		return []versionComparator{{">=", base}, {"<", upper}}, nil
+
+	case "~":
+		upper := Version{base.Major, base.Minor + 1, 0}
+		if given <= 1 {
+			upper = Version{base.Major + 1, 0, 0}
+		}
+		return []versionComparator{{">=", base}, {"<", upper}}, nil
+
+	case ">", "<=":
+		// >1.2 表示 >=1.3.0，<=1.2 表示 <1.3.0
+		if upper, ok := upperFor(given); ok {
+			if op == ">" {
+				return []versionComparator{{">=", upper}}, nil
+			}
+			return []versionComparator{{"<", upper}}, nil
+		}
+		if given == 0 {
+			if op == ">" {
+				return []versionComparator{{"<", Version{}}}, nil
+			}
+			return nil, nil
+		}
+		return []versionComparator{{op, base}}, nil
+
+	case ">=", "<":
+		if given == 0 {
+			if op == "<" {
+				return []versionComparator{{"<", Version{}}}, nil
+			}
+			return nil, nil
+		}
+		return []versionComparator{{op, base}}, nil
+	}
+
+	// 精确版本或通配版本
+	if upper, ok := upperFor(given); ok {
+		return []versionComparator{{">=", base}, {"<", upper}}, nil
+	}
+	if given == 0 {
+		return nil, nil
+	}
+	return []versionComparator{{"=", base}}, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestSatisfiesVersionRange(t *testing.T) {
+	cases := []struct {
+		version  string
+		rng      string
+		expected bool
+	}{
+		{"1.2.3", "^1.2.0", true},
+		{"2.0.0", "^1.2.0", false},
+		{"0.2.5", "^0.2.1", true},
+		{"0.3.0", "^0.2.1", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.5.0", ">=1.2 <2", true},
+		{"2.0.0", ">=1.2 <2", false},
+		{"1.4.0", "1.x", true},
+		{"2.4.0", "1.x || 2.x", true},
+		{"3.0.0", "1.x || 2.x", false},
+		{"1.5.0", "1.2.0 - 1.6", true},
+		{"1.7.0", "1.2.0 - 1.6", false},
+		{"v20.11.1", ">= 18", true},
+		{"16.20.0", ">=18", false},
+		{"1.0.0-beta.1", "1.0.0", true},
+		{"5.0.0", "*", true},
+		{"1.3.0", ">1.2", true},
+		{"1.2.9", ">1.2", false},
+		{"1.2.9", "<=1.2", true},
+	}
+
+	for _, c := range cases {
+		got, err := SatisfiesVersionRange(c.version, c.rng)
+		if err != nil {
+			t.Errorf("%s in %q: unexpected error: %v", c.version, c.rng, err)
+			continue
+		}
+		if got != c.expected {
+			t.Errorf("%s in %q: expected %v, got %v", c.version, c.rng, c.expected, got)
+		}
+	}
+
+	if _, err := SatisfiesVersionRange("1.0.0", ">=abc"); err == nil {
+		t.Error("expected error for invalid range")
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SitemapMaxURLs 是单个 sitemap 文件允许的最大 URL 数量
+const SitemapMaxURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapURL 是 sitemap 中的一个页面
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []SitemapURL `xml:"sitemap"`
+}
+
+// WriteSitemap 将 urls 写入 dir/sitemap.xml；超过 maxURLs 个时拆分为 sitemap-1.xml、sitemap-2.xml 等，
+// sitemap.xml 则作为索引。rootURL 是站点根目录的地址（以 / 结尾），返回写入的文件名
+func WriteSitemap(dir, rootURL string, urls []SitemapURL, maxURLs int) ([]string, error) {
+	if maxURLs <= 0 || maxURLs > SitemapMaxURLs {
+		maxURLs = SitemapMaxURLs
+	}
+	if len(urls) <= maxURLs {
+		return []string{"sitemap.xml"}, writeXMLFile(filepath.Join(dir, "sitemap.xml"), sitemapURLSet{Xmlns: sitemapNamespace, URLs: urls})
+	}
+
+	var written []string
+	index := sitemapIndex{Xmlns: sitemapNamespace}
+	for i := 0; i*maxURLs < len(urls); i++ {
+		part := urls[i*maxURLs : min((i+1)*maxURLs, len(urls))]
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if err := writeXMLFile(filepath.Join(dir, name), sitemapURLSet{Xmlns: sitemapNamespace, URLs: part}); err != nil {
+			return nil, err
+		}
+		written = append(written, name)
+		index.Sitemaps = append(index.Sitemaps, SitemapURL{Loc: rootURL + name, LastMod: latestLastMod(part)})
+	}
+	if err := writeXMLFile(filepath.Join(dir, "sitemap.xml"), index); err != nil {
+		return nil, err
+	}
+	return append([]string{"sitemap.xml"}, written...), nil
+}
+
+// latestLastMod 返回一组页面中最新的修改时间
+func latestLastMod(urls []SitemapURL) string {
+	var latest time.Time
+	value := ""
+	for _, u := range urls {
+		if t, ok := ParseLastMod(u.LastMod); ok && t.After(latest) {
+			latest, value = t, u.LastMod
+		}
+	}
+	return value
+}
+
+// ParseLastMod 解析 W3C 日期时间格式（sitemap 的 lastmod 使用的格式）
+func ParseLastMod(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// writeXMLFile 以带 XML 声明的缩进格式写入文件
+func writeXMLFile(path string, v interface{}) error {
+	data, err := marshalXML(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
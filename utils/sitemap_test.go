@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	dir := t.TempDir()
+	urls := []SitemapURL{
+		{Loc: "https://example.com/", LastMod: "2024-01-02"},
+		{Loc: "https://example.com/a/", LastMod: "2024-03-01T10:00:00+08:00"},
+		{Loc: "https://example.com/b/"},
+	}
+
+	files, err := WriteSitemap(dir, "https://example.com/", urls, 0)
+	if err != nil {
+		t.Fatalf("WriteSitemap() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("WriteSitemap() wrote %v, want only sitemap.xml", files)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	for _, want := range []string{`<?xml version="1.0" encoding="UTF-8"?>`, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		`<loc>https://example.com/a/</loc>`, `<lastmod>2024-01-02</lastmod>`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("sitemap.xml does not contain %s:\n%s", want, data)
+		}
+	}
+
+	files, err = WriteSitemap(dir, "https://example.com/", urls, 2)
+	if err != nil {
+		t.Fatalf("WriteSitemap() error = %v", err)
+	}
+	if strings.Join(files, ",") != "sitemap.xml,sitemap-1.xml,sitemap-2.xml" {
+		t.Errorf("WriteSitemap() wrote %v", files)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	for _, want := range []string{"<sitemapindex", "<loc>https://example.com/sitemap-2.xml</loc>", "<lastmod>2024-03-01T10:00:00+08:00</lastmod>"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("sitemap index does not contain %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestParsePageMeta(t *testing.T) {
+	page := `<html><head>
+<title> Hello &amp; welcome </title>
+<link rel="alternate canonical" href="/posts/hello/">
+<meta name="robots" content="index, nofollow">
+<meta property="article:published_time" content="2024-01-01">
+<meta property="article:modified_time" content="2024-02-01T08:00:00Z">
+</head><body><link rel="canonical" href="/ignored/"></body></html>`
+
+	meta := ParsePageMeta([]byte(page))
+	if meta.Title != "Hello & welcome" || meta.Canonical != "/posts/hello/" ||
+		meta.Modified != "2024-02-01T08:00:00Z" || meta.Published != "2024-01-01" {
+		t.Errorf("ParsePageMeta() = %+v", meta)
+	}
+	if meta.NoIndex() {
+		t.Errorf("NoIndex() = true for %q", meta.Robots)
+	}
+	if !(PageMeta{Robots: "noindex, follow"}).NoIndex() {
+		t.Errorf("NoIndex() = false for noindex")
+	}
+}
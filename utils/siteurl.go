@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// SiteResolver 将页面中的 URL 解析为站点输出目录中的路径
+type SiteResolver struct {
+	// BasePath 站点的基础路径，根相对 URL 以它开头，空字符串等同于 "/"
+	BasePath string
+	// SiteHost 站点的主机名，指向它的绝对 URL 同样视为站内链接
+	SiteHost string
+}
+
+// Resolve 返回 ref 指向的站内路径（相对于输出目录，"" 表示根目录）和锚点；fromDir 是引用所在文件的目录。
+// 以 / 结尾的路径保留结尾的 /。指向其他站点或不是路径的 URL（mailto:、data: 等）返回 false
+func (r SiteResolver) Resolve(ref, fromDir string) (target, fragment string, ok bool) {
+	urlPath := ref
+	if i := strings.IndexByte(urlPath, '#'); i >= 0 {
+		urlPath, fragment = urlPath[:i], urlPath[i+1:]
+		if unescaped, err := url.PathUnescape(fragment); err == nil {
+			fragment = unescaped
+		}
+	}
+	if i := strings.IndexByte(urlPath, '?'); i >= 0 {
+		urlPath = urlPath[:i]
+	}
+	if urlPath == "" {
+		// 只有锚点或查询参数，指向当前页面
+		return "", fragment, false
+	}
+
+	if strings.HasPrefix(urlPath, "//") || strings.Contains(urlPath, "://") {
+		u, err := url.Parse(urlPath)
+		if err != nil || r.SiteHost == "" || !strings.EqualFold(u.Host, r.SiteHost) {
+			return "", "", false
+		}
+		urlPath = u.EscapedPath()
+		if urlPath == "" {
+			urlPath = "/"
+		}
+	} else if strings.Contains(urlPath, ":") && !strings.Contains(strings.SplitN(urlPath, ":", 2)[0], "/") {
+		// data:、mailto:、javascript: 等
+		return "", "", false
+	}
+
+	basePath := r.BasePath
+	if basePath == "" {
+		basePath = "/"
+	}
+
+	if strings.HasPrefix(urlPath, "/") {
+		if urlPath+"/" == basePath {
+			urlPath = basePath
+		}
+		if !strings.HasPrefix(urlPath, basePath) {
+			return "", "", false
+		}
+		target = strings.TrimPrefix(urlPath, basePath)
+	} else {
+		target = path.Join(fromDir, urlPath)
+	}
+
+	isDir := strings.HasSuffix(urlPath, "/")
+	target, err := url.PathUnescape(path.Clean("/" + target))
+	if err != nil {
+		return "", "", false
+	}
+	// path.Clean 会去掉开头多余的 ..，因此用 fromDir 之外的目录需要单独判断
+	if !strings.HasPrefix(urlPath, "/") && strings.HasPrefix(path.Clean(path.Join(fromDir, urlPath)), "..") {
+		return "", "", false
+	}
+	target = strings.TrimPrefix(target, "/")
+	if isDir && target != "" {
+		target += "/"
+	}
+	return target, fragment, true
+}
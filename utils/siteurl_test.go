@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestSiteResolverResolve(t *testing.T) {
+	r := SiteResolver{BasePath: "/blog/", SiteHost: "example.com"}
+	tests := []struct {
+		ref, fromDir     string
+		target, fragment string
+		ok               bool
+	}{
+		{"/blog/posts/a/", "posts", "posts/a/", "", true},
+		{"/blog", ".", "", "", true},
+		{"/blog/img/a%20b.png?v=1#x", ".", "img/a b.png", "x", true},
+		{"../img/a.png", "posts", "img/a.png", "", true},
+		{"../../a.png", "posts", "", "", false},
+		{"https://example.com/blog/about/#team", "posts", "about/", "team", true},
+		{"https://other.com/blog/about/", "posts", "", "", false},
+		{"/other/", ".", "", "", false},
+		{"#top", ".", "", "top", false},
+		{"mailto:someone@example.com", ".", "", "", false},
+		{"data:image/png;base64,AAAA", ".", "", "", false},
+	}
+
+	for _, tt := range tests {
+		target, fragment, ok := r.Resolve(tt.ref, tt.fromDir)
+		if target != tt.target || fragment != tt.fragment || ok != tt.ok {
+			t.Errorf("Resolve(%q, %q) = %q, %q, %v, want %q, %q, %v",
+				tt.ref, tt.fromDir, target, fragment, ok, tt.target, tt.fragment, tt.ok)
+		}
+	}
+}
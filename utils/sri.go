@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// pinnedVersionPattern 匹配 URL 路径中固定的完整版本号，如 pkg@1.2.3 或 /ajax/libs/pkg/1.2.3/
+var pinnedVersionPattern = regexp.MustCompile(`(?:@|/)v?\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.-]+)?(?:/|$)`)
+
+// IntegrityHash 返回内容的 SRI 值（如 sha384-...），algorithm 为 sha256、sha384 或 sha512
+func IntegrityHash(data []byte, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported integrity algorithm '%s', expected sha256, sha384 or sha512", algorithm)
+	}
+	h.Write(data)
+	return algorithm + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// IsPinnedURL 判断 https 地址的路径中是否固定了完整版本号，只有这样的 CDN 资源内容不会变化
+func IsPinnedURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	return pinnedVersionPattern.MatchString(u.Path)
+}
+
+// FetchIntegrity 下载远程资源并返回它的 SRI 值
+func FetchIntegrity(rawURL, algorithm, userAgent string, timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.New(describeRequestError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return IntegrityHash(data, algorithm)
+}
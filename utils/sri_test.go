@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIntegrityHash(t *testing.T) {
+	got, err := IntegrityHash([]byte("alert('hi');"), "sha256")
+	if err != nil {
+		t.Fatalf("IntegrityHash() error = %v", err)
+	}
+	if want := "sha256-S3glexDivN1XnfRGec5uF4Y7TT2a/rcrADlE/zj4maA="; got != want {
+		t.Errorf("IntegrityHash() = %s, want %s", got, want)
+	}
+	if _, err := IntegrityHash(nil, "md5"); err == nil {
+		t.Error("IntegrityHash() accepted md5")
+	}
+}
+
+func TestIsPinnedURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://cdn.jsdelivr.net/npm/katex@0.16.9/dist/katex.min.css":              true,
+		"https://unpkg.com/vue@3.4.21":                                              true,
+		"https://cdnjs.cloudflare.com/ajax/libs/jquery/3.7.1/jquery.min.js":         true,
+		"https://cdn.jsdelivr.net/npm/mermaid@10.9.0-rc.1/dist/mermaid.esm.min.mjs": true,
+		"https://cdn.jsdelivr.net/npm/katex@0.16/dist/katex.min.css":                false,
+		"https://unpkg.com/vue@latest":                                              false,
+		"https://example.com/app.js?v=1.2.3":                                        false,
+		"http://unpkg.com/vue@3.4.21":                                               false,
+	}
+	for u, want := range tests {
+		if got := IsPinnedURL(u); got != want {
+			t.Errorf("IsPinnedURL(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
+
+func TestCSPString(t *testing.T) {
+	csp := NewCSP()
+	csp.Add("img-src", "https://img.example.com", "'self'", "data:")
+	csp.Add("script-src", "'sha256-abc='", "'self'", "'self'")
+	csp.Add("default-src", "'self'")
+	want := "default-src 'self'; script-src 'self' 'sha256-abc='; img-src 'self' data: https://img.example.com"
+	if got := csp.String(); got != want {
+		t.Errorf("CSP.String() = %s, want %s", got, want)
+	}
+
+	for ref, want := range map[string]string{"//cdn.example.com/a.js": "https://cdn.example.com", "data:image/png;base64,xx": "data:", "/a.js": ""} {
+		if got, _ := CSPSource(ref); got != want {
+			t.Errorf("CSPSource(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestFetchIntegrity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lib@1.0.0/lib.js" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("alert('hi');"))
+	}))
+	defer server.Close()
+
+	got, err := FetchIntegrity(server.URL+"/lib@1.0.0/lib.js", "sha256", "", time.Second)
+	if err != nil || got != "sha256-S3glexDivN1XnfRGec5uF4Y7TT2a/rcrADlE/zj4maA=" {
+		t.Errorf("FetchIntegrity() = %s, %v", got, err)
+	}
+	if _, err := FetchIntegrity(server.URL+"/missing.js", "sha256", "", time.Second); err == nil || err.Error() != "HTTP 404" {
+		t.Errorf("FetchIntegrity() error = %v, want HTTP 404", err)
+	}
+}
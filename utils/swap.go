@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+)
+
+// SwapDirectory 用 newDir 替换 liveDir，替换后原 liveDir 的内容位于 newDir。
+// 支持时使用原子交换（Linux renameat2），否则依次重命名，失败时恢复原目录；
+// liveDir 不存在时直接重命名
+func SwapDirectory(newDir, liveDir string) error {
+	if !FileExists(liveDir) {
+		return os.Rename(newDir, liveDir)
+	}
+
+	if err := exchangeDirectories(newDir, liveDir); err == nil {
+		return nil
+	}
+
+	// 不支持原子交换时，先把旧目录移开再重命名新目录
+	asideDir := newDir + ".old"
+	if err := os.RemoveAll(asideDir); err != nil {
+		return err
+	}
+	if err := os.Rename(liveDir, asideDir); err != nil {
+		return err
+	}
+	if err := os.Rename(newDir, liveDir); err != nil {
+		if restoreErr := os.Rename(asideDir, liveDir); restoreErr != nil {
+			return fmt.Errorf("%v (failed to restore '%s': %v)", err, liveDir, restoreErr)
+		}
+		return err
+	}
+	return os.Rename(asideDir, newDir)
+}
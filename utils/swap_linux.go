@@ -0,0 +1,10 @@
+//go:build linux
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// exchangeDirectories 原子地交换两个路径
+func exchangeDirectories(a, b string) error {
+	return unix.Renameat2(unix.AT_FDCWD, a, unix.AT_FDCWD, b, unix.RENAME_EXCHANGE)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package utils
+
+import "errors"
+
+// exchangeDirectories 在不支持原子交换的平台上总是返回错误
+func exchangeDirectories(a, b string) error {
+	return errors.New("atomic exchange is not supported on this platform")
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSwapDirectory(t *testing.T) {
+	dir := t.TempDir()
+	newDir := filepath.Join(dir, "next")
+	liveDir := filepath.Join(dir, "live")
+	writeTestFile(t, filepath.Join(newDir, "index.html"), "new")
+	writeTestFile(t, filepath.Join(liveDir, "index.html"), "old")
+
+	if err := SwapDirectory(newDir, liveDir); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, filepath.Join(liveDir, "index.html")); got != "new" {
+		t.Errorf("expected live directory to have the new content, got %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(newDir, "index.html")); got != "old" {
+		t.Errorf("expected the old content to be moved to the new directory, got %q", got)
+	}
+	if FileExists(newDir + ".old") {
+		t.Error("expected no directory to be left aside")
+	}
+}
+
+func TestSwapDirectoryWithoutLiveDir(t *testing.T) {
+	dir := t.TempDir()
+	newDir := filepath.Join(dir, "next")
+	liveDir := filepath.Join(dir, "live")
+	writeTestFile(t, filepath.Join(newDir, "index.html"), "new")
+
+	if err := SwapDirectory(newDir, liveDir); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, filepath.Join(liveDir, "index.html")); got != "new" {
+		t.Errorf("expected live directory to have the new content, got %q", got)
+	}
+	if FileExists(newDir) {
+		t.Error("expected the new directory to be renamed")
+	}
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// URLCheckOptions 是检查外部链接的设置
+type URLCheckOptions struct {
+	// Concurrency 同时检查的 URL 数
+	Concurrency int
+	// Timeout 单个请求的超时时间
+	Timeout time.Duration
+	// UserAgent 请求使用的 User-Agent
+	UserAgent string
+}
+
+// URLCheckResult 是一个 URL 的检查结果
+type URLCheckResult struct {
+	Status    int       `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// OK 判断 URL 是否可以访问
+func (r URLCheckResult) OK() bool {
+	return r.Error == "" && r.Status >= 200 && r.Status < 400
+}
+
+// Permanent 判断结果是否可以缓存：成功或明确不存在（404、410），
+// 网络错误、5xx 和 429 等可能是暂时的
+func (r URLCheckResult) Permanent() bool {
+	return r.OK() || r.Status == http.StatusNotFound || r.Status == http.StatusGone
+}
+
+// Describe 返回结果的简短说明
+func (r URLCheckResult) Describe() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// CheckURLs 并行检查 URL 是否可以访问，先发送 HEAD 请求，服务器不支持时改用 GET
+func CheckURLs(urls []string, opts URLCheckOptions) map[string]URLCheckResult {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	client := &http.Client{Timeout: opts.Timeout}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]URLCheckResult)
+		queue   = make(chan string)
+	)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawURL := range queue {
+				result := checkURL(client, rawURL, opts.UserAgent)
+				mu.Lock()
+				results[rawURL] = result
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, rawURL := range urls {
+		queue <- rawURL
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// checkURL 检查单个 URL
+func checkURL(client *http.Client, rawURL, userAgent string) URLCheckResult {
+	result := URLCheckResult{CheckedAt: time.Now()}
+
+	status, err := requestStatus(client, http.MethodHead, rawURL, userAgent)
+	// 有些服务器不支持 HEAD 或对其返回错误的状态，用 GET 再确认一次
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented ||
+		status == http.StatusForbidden || status == http.StatusNotFound) {
+		status, err = requestStatus(client, http.MethodGet, rawURL, userAgent)
+	}
+	if err != nil {
+		result.Error = describeRequestError(err)
+		return result
+	}
+	result.Status = status
+	return result
+}
+
+// requestStatus 发送请求并返回状态码，GET 请求只读取少量响应内容
+func requestStatus(client *http.Client, method, rawURL, userAgent string) (int, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.CopyN(io.Discard, resp.Body, 4096)
+	return resp.StatusCode, nil
+}
+
+// describeRequestError 去掉错误中重复的请求方法和 URL
+func describeRequestError(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			return "timeout"
+		}
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results := CheckURLs([]string{server.URL + "/ok", server.URL + "/no-head", server.URL + "/missing", server.URL + "/slow"},
+		URLCheckOptions{Concurrency: 2, Timeout: 50 * time.Millisecond})
+
+	if r := results[server.URL+"/ok"]; !r.OK() {
+		t.Errorf("/ok = %s, want OK", r.Describe())
+	}
+	if r := results[server.URL+"/no-head"]; !r.OK() {
+		t.Errorf("/no-head = %s, want OK after falling back to GET", r.Describe())
+	}
+	if r := results[server.URL+"/missing"]; r.OK() || r.Status != http.StatusNotFound || !r.Permanent() {
+		t.Errorf("/missing = %s, want a permanent 404", r.Describe())
+	}
+	if r := results[server.URL+"/slow"]; r.OK() || r.Describe() != "timeout" || r.Permanent() {
+		t.Errorf("/slow = %s, want a timeout that is not cached", r.Describe())
+	}
+}
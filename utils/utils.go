@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
 // CheckCommand 检查命令是否存在
@@ -30,26 +32,46 @@ func GetCommandVersion(command string, versionFlag string) (string, error) {
 
 // PrintSuccess 打印成功信息
 func PrintSuccess(message string) {
-	green := color.New(color.FgGreen, color.Bold)
-	green.Printf("✓ %s\n", message)
+	FprintSuccess(color.Output, message)
 }
 
 // PrintError 打印错误信息
 func PrintError(message string) {
-	red := color.New(color.FgRed, color.Bold)
-	red.Printf("✗ %s\n", message)
+	FprintError(color.Output, message)
 }
 
 // PrintWarning 打印警告信息
 func PrintWarning(message string) {
-	yellow := color.New(color.FgYellow, color.Bold)
-	yellow.Printf("⚠ %s\n", message)
+	FprintWarning(color.Output, message)
 }
 
 // PrintInfo 打印信息
 func PrintInfo(message string) {
+	FprintInfo(color.Output, message)
+}
+
+// FprintSuccess 向指定输出打印成功信息
+func FprintSuccess(w io.Writer, message string) {
+	green := color.New(color.FgGreen, color.Bold)
+	green.Fprintf(w, "✓ %s\n", message)
+}
+
+// FprintError 向指定输出打印错误信息
+func FprintError(w io.Writer, message string) {
+	red := color.New(color.FgRed, color.Bold)
+	red.Fprintf(w, "✗ %s\n", message)
+}
+
+// FprintWarning 向指定输出打印警告信息
+func FprintWarning(w io.Writer, message string) {
+	yellow := color.New(color.FgYellow, color.Bold)
+	yellow.Fprintf(w, "⚠ %s\n", message)
+}
+
+// FprintInfo 向指定输出打印信息
+func FprintInfo(w io.Writer, message string) {
 	blue := color.New(color.FgBlue, color.Bold)
-	blue.Printf("ℹ %s\n", message)
+	blue.Fprintf(w, "ℹ %s\n", message)
 }
 
 // ColorText 返回带颜色的文本
@@ -93,11 +115,50 @@ func RunCommandInDir(dir, name string, args ...string) error {
 	return cmd.Run()
 }
 
+// CommandOptions 是执行命令时的可选设置
+type CommandOptions struct {
+	Dir    string    // 工作目录
+	Env    []string  // 追加到当前环境的变量（KEY=VALUE）
+	Stdout io.Writer // 默认为 os.Stdout
+	Stderr io.Writer // 默认为 os.Stderr
+}
+
+// RunCommandWithOptions 按指定的目录、环境变量和输出执行命令
+func RunCommandWithOptions(opts CommandOptions, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	cmd.Stdout = os.Stdout
+	if opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
+	}
+	cmd.Stderr = os.Stderr
+	if opts.Stderr != nil {
+		cmd.Stderr = opts.Stderr
+	}
+	return cmd.Run()
+}
+
+// ShellCommand 返回通过系统 shell 执行命令行的程序和参数
+func ShellCommand(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
 // NewCommand 创建一个新的命令，用于获取输出
 func NewCommand(name string, args ...string) *exec.Cmd {
 	return exec.Command(name, args...)
 }
 
+// IsTerminal 检查文件是否是终端
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
 // FileExists 检查文件是否存在
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -184,6 +245,11 @@ func GetProjectRoot() (string, error) {
 	return os.Getwd()
 }
 
+// GetStateDir 获取项目中 wordma CLI 的状态目录（.wordma）
+func GetStateDir(projectRoot string) string {
+	return filepath.Join(projectRoot, ".wordma")
+}
+
 // CopyDirectory 复制整个目录
 func CopyDirectory(src, dst string) error {
 	// 获取源目录信息
@@ -227,6 +293,34 @@ func CopyDirectory(src, dst string) error {
 	return nil
 }
 
+// MoveDirectory 移动目录，无法直接重命名（如跨磁盘）时复制后删除源目录
+func MoveDirectory(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := CopyDirectory(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// HasFilesModifiedSince 检查目录中是否有在指定时间之后修改的文件
+func HasFilesModifiedSince(dir string, since time.Time) (bool, error) {
+	found := false
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.ModTime().Before(since) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
+
 // CopyFile 复制单个文件
 func CopyFile(src, dst string) error {
 	// 打开源文件
@@ -385,4 +479,14 @@ temp/
 
 	gitignorePath := filepath.Join(deployPath, ".gitignore")
 	return os.WriteFile(gitignorePath, []byte(gitignoreContent), 0644)
-}
\ No newline at end of file
+}
+
+// Contains 判断字符串切片中是否有该值
+func Contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}